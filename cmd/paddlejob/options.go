@@ -0,0 +1,241 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+	"github.com/paddlepaddle/paddlejob/pkg/master"
+	"github.com/paddlepaddle/paddlejob/pkg/updater"
+)
+
+// options are the command line options of the operator.
+type options struct {
+	kubeconfig string
+
+	installCRD      bool
+	crdReadyTimeout time.Duration
+
+	defaultImage         string
+	defaultRequests      string
+	defaultLimits        string
+	defaultNodeSelector  string
+	spreadPservers       bool
+	pserverFailurePolicy string
+
+	createServiceAccounts bool
+	progressAnnotation    bool
+	acceleratorResources  string
+	failureLogLines       int64
+	readyTimeout          time.Duration
+	releaseTimeout        time.Duration
+	unschedulableTimeout  time.Duration
+
+	webhookAddr   string
+	tlsCertFile   string
+	tlsKeyFile    string
+	webhookCAFile string
+
+	webhookService          string
+	webhookServiceNamespace string
+
+	masterImage string
+
+	operatorNamespaceLabels string
+
+	hostPortRange     string
+	hostPortConfigMap string
+
+	sweepInterval time.Duration
+	orphanPolicy  string
+	sweepDryRun   bool
+
+	// master runs the task dispatcher master of a job instead of the
+	// operator.
+	master                bool
+	masterAddr            string
+	masterDataset         string
+	masterPasses          int
+	masterChunksPerTask   int
+	masterTaskTimeout     time.Duration
+	masterMaxTaskFailures int
+	masterStatePath       string
+}
+
+func (o *options) addFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", "", "Path to a kube config. Only required if out-of-cluster.")
+
+	fs.BoolVar(&o.installCRD, "install-crd", false, "Create or upgrade the PaddleJob CRD on startup.")
+	fs.DurationVar(&o.crdReadyTimeout, "crd-ready-timeout", time.Minute, "How long to wait for the installed CRD to be established.")
+
+	fs.StringVar(&o.defaultImage, "default-image", "", "Image of PaddleJobs that do not specify one.")
+	fs.StringVar(&o.defaultRequests, "default-requests", "", "Resource requests of pservers and trainers that do not specify any, e.g. cpu=1,memory=1Gi.")
+	fs.StringVar(&o.defaultLimits, "default-limits", "", "Resource limits of pservers and trainers that do not specify any, e.g. cpu=2,memory=2Gi.")
+	fs.StringVar(&o.defaultNodeSelector, "default-node-selector", "", "Node selector of PaddleJobs that do not specify one, e.g. pool=paddle.")
+	fs.BoolVar(&o.spreadPservers, "spread-pservers", true, "Prefer to schedule the pservers of a PaddleJob on different nodes unless the job sets their affinity.")
+
+	fs.StringVar(&o.pserverFailurePolicy, "default-pserver-failure-policy", string(padv1.PserverFailurePolicyFail), "Pserver failure policy of PaddleJobs that do not specify one: Fail, RestartJob or Reconnect.")
+
+	fs.BoolVar(&o.createServiceAccounts, "create-service-accounts", false, "Create a service account only allowed to read the pods of its namespace for the trainers of PaddleJobs that do not set serviceAccountName.")
+	fs.BoolVar(&o.progressAnnotation, "progress-annotation", false, "Also allow the service accounts created by --create-service-accounts to patch the pods of their namespace, so the trainers can report their progress. Any trainer may then change any pod of the namespace.")
+
+	fs.StringVar(&o.acceleratorResources, "accelerator-resources", "nvidia.com/gpu,alpha.kubernetes.io/nvidia-gpu", "Comma separated resources counted as accelerator devices of trainers.")
+
+	fs.Int64Var(&o.failureLogLines, "failure-log-lines", 20, "Number of log lines of the first failed pod of a PaddleJob kept in its status.")
+
+	fs.DurationVar(&o.readyTimeout, "ready-timeout", 0, "How long the pservers of a PaddleJob may take to become ready before the job fails, 0 for no limit.")
+	fs.DurationVar(&o.releaseTimeout, "release-timeout", 5*time.Minute, "How long the trainers of a restarted PaddleJob may take to be deleted before the job fails, 0 for no limit.")
+	fs.DurationVar(&o.unschedulableTimeout, "unschedulable-timeout", 0, "How long a pod of a PaddleJob may be unschedulable before the job fails, 0 for no limit.")
+
+	fs.StringVar(&o.webhookAddr, "webhook-addr", "", "Address the admission and conversion webhooks listen on, e.g. :8443. Disabled if empty.")
+	fs.StringVar(&o.tlsCertFile, "tls-cert-file", "/etc/webhook/certs/cert.pem", "TLS certificate of the webhooks.")
+	fs.StringVar(&o.tlsKeyFile, "tls-private-key-file", "/etc/webhook/certs/key.pem", "TLS private key of the webhooks.")
+	fs.StringVar(&o.webhookCAFile, "webhook-ca-file", "", "CA of the webhook certificate, set as the CA bundle of the conversion webhook by --install-crd. The CA bundle of the installed CRD is kept if empty.")
+	fs.StringVar(&o.webhookService, "webhook-service", "paddle-operator-webhook", "Service of the conversion webhook set by --install-crd.")
+	fs.StringVar(&o.webhookServiceNamespace, "webhook-service-namespace", "", "Namespace of the service of the conversion webhook set by --install-crd, the namespace of the operator if empty.")
+
+	fs.StringVar(&o.operatorNamespaceLabels, "operator-namespace-labels", "", "Labels of the operator namespace, e.g. name=paddle-operator. The network policies of PaddleJobs let the pods of the matching namespaces in.")
+
+	fs.StringVar(&o.hostPortRange, "host-port-range", "", "Host ports allocated to the PaddleJobs using the host network, e.g. 20000-29999. The ports of their spec are used as is if empty.")
+	fs.StringVar(&o.hostPortConfigMap, "host-port-configmap", "paddle-operator-host-ports", "ConfigMap in the namespace of the operator persisting the host port allocations.")
+
+	fs.DurationVar(&o.sweepInterval, "sweep-interval", 10*time.Minute, "How often the replicasets, jobs and pods left behind by deleted PaddleJobs are looked for, 0 to disable.")
+	fs.StringVar(&o.orphanPolicy, "orphan-policy", string(updater.OrphanPolicyReport), "What is done with the children of deleted PaddleJobs: Report logs them and records a warning event on them, Delete deletes them.")
+	fs.BoolVar(&o.sweepDryRun, "sweep-dry-run", false, "Only log what --orphan-policy would do with the children of deleted PaddleJobs.")
+
+	fs.StringVar(&o.masterImage, "master-image", "", "Image of the task dispatcher master of PaddleJobs that do not specify one, it runs paddlejob --master.")
+
+	fs.BoolVar(&o.master, "master", false, "Run the task dispatcher master of a PaddleJob instead of the operator.")
+	fs.StringVar(&o.masterAddr, "master-addr", ":8080", "Address the master listens on.")
+	fs.StringVar(&o.masterDataset, "master-dataset", "", "Manifest of the dataset listing one chunk per line.")
+	fs.IntVar(&o.masterPasses, "master-passes", 1, "Number of passes over the dataset.")
+	fs.IntVar(&o.masterChunksPerTask, "master-chunks-per-task", 1, "Number of chunks of a task.")
+	fs.DurationVar(&o.masterTaskTimeout, "master-task-timeout", 10*time.Minute, "How long a trainer may hold a task before it is given to another trainer.")
+	fs.IntVar(&o.masterMaxTaskFailures, "master-max-task-failures", 3, "Number of times a task may fail in a pass before it is dropped, 0 for no limit.")
+	fs.StringVar(&o.masterStatePath, "master-state-path", "", "File the task queue is persisted in to survive master restarts. Not persisted if empty.")
+}
+
+// config builds the updater configuration from the options.
+func (o *options) config() (*updater.Config, error) {
+	c := &updater.Config{}
+	c.Defaults.Image = o.defaultImage
+	c.Defaults.SpreadPservers = o.spreadPservers
+	switch policy := padv1.PserverFailurePolicy(o.pserverFailurePolicy); policy {
+	case padv1.PserverFailurePolicyFail, padv1.PserverFailurePolicyRestartJob, padv1.PserverFailurePolicyReconnect:
+		c.Defaults.PserverFailurePolicy = policy
+	default:
+		return nil, fmt.Errorf("invalid --default-pserver-failure-policy: %v", o.pserverFailurePolicy)
+	}
+	c.CreateServiceAccounts = o.createServiceAccounts
+	c.ProgressAnnotation = o.progressAnnotation
+	c.FailureLogLines = o.failureLogLines
+	c.ReadyTimeout = o.readyTimeout
+	c.ReleaseTimeout = o.releaseTimeout
+	c.UnschedulableTimeout = o.unschedulableTimeout
+	c.MasterImage = o.masterImage
+	for _, name := range strings.Split(o.acceleratorResources, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			c.AcceleratorResources = append(c.AcceleratorResources, corev1.ResourceName(name))
+		}
+	}
+
+	var err error
+	if c.Defaults.Resources.Requests, err = parseResourceList(o.defaultRequests); err != nil {
+		return nil, fmt.Errorf("invalid --default-requests: %v", err)
+	}
+	if c.Defaults.Resources.Limits, err = parseResourceList(o.defaultLimits); err != nil {
+		return nil, fmt.Errorf("invalid --default-limits: %v", err)
+	}
+	if o.defaultNodeSelector != "" {
+		selector, err := labels.ConvertSelectorToLabelsMap(o.defaultNodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --default-node-selector: %v", err)
+		}
+		c.Defaults.NodeSelector = selector
+	}
+	if o.operatorNamespaceLabels != "" {
+		selector, err := labels.ConvertSelectorToLabelsMap(o.operatorNamespaceLabels)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --operator-namespace-labels: %v", err)
+		}
+		c.OperatorNamespaceLabels = selector
+	}
+	return c, nil
+}
+
+// hostPorts builds the host port allocator from the options, it is nil if
+// no host port range is given.
+func (o *options) hostPorts(client kubernetes.Interface, namespace string) (*updater.PortAllocator, error) {
+	if o.hostPortRange == "" {
+		return nil, nil
+	}
+	var first, last int
+	if _, err := fmt.Sscanf(o.hostPortRange, "%d-%d", &first, &last); err != nil || first <= 0 || last > 65535 || first > last {
+		return nil, fmt.Errorf("invalid --host-port-range: %v", o.hostPortRange)
+	}
+	return updater.NewPortAllocator(client, namespace, o.hostPortConfigMap, first, last), nil
+}
+
+// sweeperConfig builds the sweeper configuration from the options.
+func (o *options) sweeperConfig() (updater.SweeperConfig, error) {
+	c := updater.SweeperConfig{Interval: o.sweepInterval, DryRun: o.sweepDryRun}
+	switch policy := updater.OrphanPolicy(o.orphanPolicy); policy {
+	case updater.OrphanPolicyReport, updater.OrphanPolicyDelete:
+		c.Policy = policy
+	default:
+		return c, fmt.Errorf("invalid --orphan-policy: %v", o.orphanPolicy)
+	}
+	return c, nil
+}
+
+// masterConfig builds the master configuration from the options.
+func (o *options) masterConfig() master.Config {
+	return master.Config{
+		Passes:        o.masterPasses,
+		ChunksPerTask: o.masterChunksPerTask,
+		Timeout:       o.masterTaskTimeout,
+		MaxFailures:   o.masterMaxTaskFailures,
+		StatePath:     o.masterStatePath,
+	}
+}
+
+// parseResourceList parses a list like "cpu=1,memory=1Gi".
+func parseResourceList(s string) (corev1.ResourceList, error) {
+	if s == "" {
+		return nil, nil
+	}
+	pairs, err := labels.ConvertSelectorToLabelsMap(s)
+	if err != nil {
+		return nil, err
+	}
+	rl := corev1.ResourceList{}
+	for name, value := range pairs {
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		rl[corev1.ResourceName(name)] = q
+	}
+	return rl, nil
+}
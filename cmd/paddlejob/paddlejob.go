@@ -17,7 +17,10 @@ package main
 
 import (
 	"flag"
+	"io/ioutil"
+	"os"
 
+	log "github.com/golang/glog"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -25,28 +28,95 @@ import (
 	"github.com/paddlepaddle/paddlejob/pkg"
 	paddleresource "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
 	paddleJobClient "github.com/paddlepaddle/paddlejob/pkg/client/clientset/versioned"
+	"github.com/paddlepaddle/paddlejob/pkg/crd"
+	"github.com/paddlepaddle/paddlejob/pkg/master"
+	"github.com/paddlepaddle/paddlejob/pkg/webhook"
 )
 
 func main() {
-	kubeconfig := flag.String("kubeconfig", "", "Path to a kube config. Only required if out-of-cluster.")
+	opts := &options{}
+	opts.addFlags(flag.CommandLine)
+	flag.Parse()
+
+	if opts.master {
+		runMaster(opts)
+		return
+	}
+
+	config, err := opts.config()
+	if err != nil {
+		log.Fatal(err)
+	}
+	sweep, err := opts.sweeperConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Create the client config. Use kubeconfig if given, otherwise assume in-cluster.
 	var cfg *rest.Config
-	if *kubeconfig != "" {
-		cfg, _ = clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	if opts.kubeconfig != "" {
+		cfg, _ = clientcmd.BuildConfigFromFlags("", opts.kubeconfig)
 	} else {
 		cfg, _ = rest.InClusterConfig()
 	}
 
+	namespace := os.Getenv("MY_POD_NAMESPACE")
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	if opts.installCRD {
+		conversion := crd.Webhook{
+			Serve:            opts.webhookAddr != "",
+			ServiceName:      opts.webhookService,
+			ServiceNamespace: opts.webhookServiceNamespace,
+		}
+		if conversion.ServiceNamespace == "" {
+			conversion.ServiceNamespace = namespace
+		}
+		if opts.webhookCAFile != "" {
+			if conversion.CABundle, err = ioutil.ReadFile(opts.webhookCAFile); err != nil {
+				log.Fatalf("read webhook CA error: %v", err)
+			}
+		}
+		if err := crd.Install(cfg, opts.crdReadyTimeout, conversion); err != nil {
+			log.Fatalf("install CRD error: %v", err)
+		}
+	}
+
 	paddleresource.RegisterResource(cfg, &paddleresource.PaddleJob{}, &paddleresource.PaddleJobList{})
 
 	clientset, _ := kubernetes.NewForConfig(cfg)
 
+	if config.HostPorts, err = opts.hostPorts(clientset, namespace); err != nil {
+		log.Fatal(err)
+	}
+
 	client, _ := rest.RESTClientFor(cfg)
 
 	paddleJobClient, _ := paddleJobClient.NewForConfig(cfg)
 
-	controller, _ := paddlejob.New(client, clientset)
+	if opts.webhookAddr != "" {
+		server := webhook.NewServer(&config.Defaults)
+		go func() {
+			log.Fatal(server.Run(opts.webhookAddr, opts.tlsCertFile, opts.tlsKeyFile))
+		}()
+	}
+
+	controller, _ := paddlejob.New(client, clientset, config, sweep)
 
 	controller.Run(paddleJobClient)
 }
+
+// runMaster runs the task dispatcher master of a job until it fails.
+func runMaster(opts *options) {
+	chunks, err := master.ReadManifest(opts.masterDataset)
+	if err != nil {
+		log.Fatalf("read dataset manifest error: %v", err)
+	}
+	s, err := master.New(chunks, opts.masterConfig())
+	if err != nil {
+		log.Fatalf("create master error: %v", err)
+	}
+	log.Fatal(s.Run(opts.masterAddr))
+}
@@ -3,17 +3,39 @@ package v1
 import (
 	"encoding/json"
 	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
-// GPU convert Resource Limit Quantity to int
-func (s *PaddleJob) GPU() int {
-	q := s.Spec.Trainer.Resources.Limits.NvidiaGPU()
-	gpu, ok := q.AsInt64()
-	if !ok {
-		// FIXME: treat errors
-		gpu = 0
+const (
+	// ResourceNvidiaGPU is the NVIDIA GPU resource of the device plugin.
+	ResourceNvidiaGPU corev1.ResourceName = "nvidia.com/gpu"
+	// ResourceAlphaNvidiaGPU is the deprecated NVIDIA GPU resource of the kubelet.
+	ResourceAlphaNvidiaGPU corev1.ResourceName = "alpha.kubernetes.io/nvidia-gpu"
+)
+
+// DefaultAcceleratorResources are the resources counted as accelerator
+// devices when the operator is not configured with other ones.
+var DefaultAcceleratorResources = []corev1.ResourceName{ResourceNvidiaGPU, ResourceAlphaNvidiaGPU}
+
+// Devices returns the number of accelerator devices of the resources names
+// in r. The limit of a resource is used if it is set, the request otherwise,
+// as the limit of an extended resource defaults its request.
+func Devices(r *corev1.ResourceRequirements, names []corev1.ResourceName) int {
+	devices := int64(0)
+	for _, name := range names {
+		if q, ok := r.Limits[name]; ok {
+			devices += q.Value()
+		} else if q, ok := r.Requests[name]; ok {
+			devices += q.Value()
+		}
 	}
-	return int(gpu)
+	return int(devices)
+}
+
+// GPU returns the number of GPUs of a trainer.
+func (s *PaddleJob) GPU() int {
+	return Devices(&s.Spec.Trainer.Resources, DefaultAcceleratorResources)
 }
 
 // NeedGPU returns true if the job need GPU resource to run.
@@ -25,3 +47,53 @@ func (s *PaddleJob) String() string {
 	b, _ := json.MarshalIndent(s, "", "   ")
 	return fmt.Sprintf("%s", b)
 }
+
+// Container returns the container named name of spec, it is added if spec
+// does not define it.
+func Container(spec *corev1.PodSpec, name string) *corev1.Container {
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == name {
+			return &spec.Containers[i]
+		}
+	}
+	spec.Containers = append(spec.Containers, corev1.Container{Name: name})
+	return &spec.Containers[len(spec.Containers)-1]
+}
+
+// ApplyTo overrides the fields of the role container c with the ones set
+// in rc.
+func (rc *RoleContainer) ApplyTo(c *corev1.Container) {
+	rc = rc.DeepCopy()
+	if rc.Image != "" {
+		c.Image = rc.Image
+	}
+	if rc.ImagePullPolicy != "" {
+		c.ImagePullPolicy = rc.ImagePullPolicy
+	}
+	if len(rc.Command) > 0 {
+		c.Command = rc.Command
+	}
+	if len(rc.Args) > 0 {
+		c.Args = rc.Args
+	}
+	c.Env = MergeEnv(c.Env, rc.Env)
+	c.EnvFrom = append(c.EnvFrom, rc.EnvFrom...)
+}
+
+// MergeEnv returns env with the variables of override added, a variable
+// of override replaces the one of env with the same name in place.
+func MergeEnv(env, override []corev1.EnvVar) []corev1.EnvVar {
+	merged := make([]corev1.EnvVar, 0, len(env)+len(override))
+	index := map[string]int{}
+	for _, vars := range [][]corev1.EnvVar{env, override} {
+		for _, e := range vars {
+			if i, ok := index[e.Name]; ok {
+				merged[i] = e
+				continue
+			}
+			index[e.Name] = len(merged)
+			merged = append(merged, e)
+		}
+	}
+	return merged
+}
@@ -38,52 +38,347 @@ func CRDName() string {
 // +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +resource:path=PaddleJob
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=paddlejobs,singular=paddlejob,shortName=tj
+// +kubebuilder:subresource:scale:specpath=.spec.trainer.min-instance,statuspath=.status.trainers
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Trainers",type=integer,JSONPath=`.spec.trainer.min-instance`
+// +kubebuilder:printcolumn:name="Pservers",type=integer,JSONPath=`.spec.pserver.min-instance`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // PaddleJob is a specification for a PaddleJob resource
 type PaddleJob struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 	Spec              PaddleJobSpec   `json:"spec"`
-	Status            PaddleJobStatus `json:"status"`
+	// +optional
+	Status PaddleJobStatus `json:"status"`
 }
 
 // PaddleJobSpec is the spec for a PaddleJob resource
 type PaddleJobSpec struct {
 	// General job attributes.
 	Image string `json:"image,omitempty"`
-	// If you want to use the hostnetwork instead of container network
-	// portmanager is necessary. About portmanager, please refer to
-	// https://github.com/PaddlePaddle/cloud/blob/develop/doc/hostnetwork/hostnetwork.md
-	HostNetwork       bool                 `json:"host_network,omitempty"`
-	Port              int                  `json:"port,omitempty"`
-	PortsNum          int                  `json:"ports_num,omitempty"`
-	PortsNumForSparse int                  `json:"ports_num_for_sparse,omitempty"`
-	Passes            int                  `json:"passes,omitempty"`
-	Volumes           []corev1.Volume      `json:"volumes"`
-	VolumeMounts      []corev1.VolumeMount `json:"VolumeMounts"`
-	NodeSelector      map[string]string    `json:"NodeSelector"`
+	// If you want to use the hostnetwork instead of container network.
+	// The operator allocates the ports of the job on the hosts when it runs
+	// with --host-port-range, Port is overwritten with the first of them.
+	HostNetwork bool `json:"host_network,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	Port int `json:"port,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	PortsNum int `json:"ports_num,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	PortsNumForSparse int `json:"ports_num_for_sparse,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	Passes int `json:"passes,omitempty"`
+	// +optional
+	// +nullable
+	Volumes []corev1.Volume `json:"volumes"`
+	// +optional
+	// +nullable
+	VolumeMounts []corev1.VolumeMount `json:"VolumeMounts"`
+	// +optional
+	// +nullable
+	NodeSelector map[string]string `json:"NodeSelector"`
+	// ImagePullSecrets are used by all the pods of the job to pull images.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// ServiceAccountName is the service account the pods of the job run as.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// StallTimeout is how long the trainers may report no progress before
+	// StallPolicy is applied. The stall detection is disabled if it is unset.
+	// +optional
+	StallTimeout *metav1.Duration `json:"stallTimeout,omitempty"`
+	// StallPolicy is applied when the trainers stall, Warn by default.
+	// +kubebuilder:validation:Enum=Warn;RestartJob;Fail
+	// +optional
+	StallPolicy StallPolicy `json:"stallPolicy,omitempty"`
+	// Checkpoint is where the pservers and the trainers save checkpoints.
+	// +optional
+	Checkpoint *CheckpointSpec `json:"checkpoint,omitempty"`
+	// Master hands out the tasks of a dataset to the trainers of a fault
+	// tolerant job.
+	// +optional
+	Master *MasterSpec `json:"master,omitempty"`
+	// WaitForPservers adds an init container to the trainers which blocks
+	// them until all the pservers are listed in the endpoints ConfigMap of
+	// the job and reachable.
+	// +optional
+	WaitForPservers bool `json:"waitForPservers,omitempty"`
+	// NetworkPolicy isolates the pods of the job, only the pods of the job
+	// and the operator may connect to them.
+	// +optional
+	NetworkPolicy bool `json:"networkPolicy,omitempty"`
+	// DriftPolicy is applied when the replicasets or the trainer job of the
+	// job are changed by hand, Revert by default. Deleted ones are always
+	// recreated.
+	// +kubebuilder:validation:Enum=Revert;Flag
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
 	//TODO(m3ngyang) simplify the structure of sub-resource(mengyang)
 	//PaddleJob components.
 	Pserver PserverSpec `json:"pserver"`
 	Trainer TrainerSpec `json:"trainer"`
+	// Heter are the heterogeneous trainers of a parameter server job, they
+	// train the dense part of the model on accelerators while the trainers
+	// and the pservers handle the sparse parameters on CPUs.
+	// +optional
+	Heter *HeterSpec `json:"heter,omitempty"`
+	// Evaluator evaluates the checkpoints of the job while it trains.
+	// +optional
+	Evaluator *EvaluatorSpec `json:"evaluator,omitempty"`
 }
 
 // PserverSpec is the spec for pservers in the paddle job
 type PserverSpec struct {
-	MinInstance int                         `json:"min-instance"`
-	MaxInstance int                         `json:"max-instance"`
-	Resources   corev1.ResourceRequirements `json:"resources"`
-	ReplicaSpec *v1beta1.ReplicaSet         `json:"replicaSpec"`
+	// +kubebuilder:validation:Minimum=1
+	MinInstance int `json:"min-instance"`
+	// +kubebuilder:validation:Minimum=1
+	MaxInstance int `json:"max-instance"`
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources"`
+	// RoleContainer overrides the container of the role.
+	RoleContainer `json:",inline"`
+	// NodeSelector of the pserver pods, it overrides the job wide one.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations of the pserver pods.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Affinity of the pserver pods, the operator spreads pservers across
+	// nodes by default.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// Template is the base of the pserver pods, the container named
+	// "pserver" is completed with the fields above.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Template *corev1.PodTemplateSpec `json:"template,omitempty"`
+	// FailurePolicy is applied when a running pserver crashes, restarts or
+	// is lost.
+	// +optional
+	FailurePolicy PserverFailurePolicy `json:"failurePolicy,omitempty"`
+	// MaxRestarts is the number of times the RestartJob policy restarts the
+	// job before failing it.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRestarts int `json:"maxRestarts,omitempty"`
+	// ReplicaSpec is generated by the operator. It is left out of the
+	// schema, which would otherwise outgrow the size limits of the CRD.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	// +nullable
+	ReplicaSpec *v1beta1.ReplicaSet `json:"replicaSpec"`
 }
 
+// RoleContainer holds the container fields of a role. They take precedence
+// over the container of the role template, which takes precedence over the
+// job wide fields and the operator defaults.
+type RoleContainer struct {
+	// Image of the role, it overrides the job wide image.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// ImagePullPolicy of the role, trainers always pull by default.
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Command replaces the paddle_k8s launch command of the role.
+	// +optional
+	Command []string `json:"command,omitempty"`
+	// Args of the command.
+	// +optional
+	Args []string `json:"args,omitempty"`
+	// Env is added to the environment of the role. The distributed training
+	// environment generated by the operator wins on conflicting names.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// EnvFrom is added to the environment sources of the role.
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+}
+
+// PserverFailurePolicy is the action taken when a pserver fails.
+// +kubebuilder:validation:Enum=Fail;RestartJob;Reconnect
+type PserverFailurePolicy string
+
+const (
+	// PserverFailurePolicyFail fails the job.
+	PserverFailurePolicyFail PserverFailurePolicy = "Fail"
+	// PserverFailurePolicyRestartJob restarts all the pservers and the
+	// trainers, which resume from their last checkpoint.
+	PserverFailurePolicyRestartJob PserverFailurePolicy = "RestartJob"
+	// PserverFailurePolicyReconnect lets the trainers of a fault tolerant
+	// job reconnect to the replaced pserver.
+	PserverFailurePolicyReconnect PserverFailurePolicy = "Reconnect"
+)
+
 // TrainerSpec is the spec for trainers in the paddle job
 type TrainerSpec struct {
-	Entrypoint   string                      `json:"entrypoint"`
-	Workspace    string                      `json:"workspace"`
-	MinInstance  int                         `json:"min-instance"`
-	MaxInstance  int                         `json:"max-instance"`
-	Resources    corev1.ResourceRequirements `json:"resources"`
-	ReplicaSpec  *batchv1.Job                `json:"replicaSpec"`
+	// +optional
+	Entrypoint string `json:"entrypoint"`
+	// +optional
+	Workspace string `json:"workspace"`
+	// +kubebuilder:validation:Minimum=1
+	MinInstance int `json:"min-instance"`
+	// +kubebuilder:validation:Minimum=1
+	MaxInstance int `json:"max-instance"`
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources"`
+	// RoleContainer overrides the container of the role.
+	RoleContainer `json:",inline"`
+	// NodeSelector of the trainer pods, it overrides the job wide one.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations of the trainer pods.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Affinity of the trainer pods.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// Template is the base of the trainer pods, the container named
+	// "trainer" is completed with the fields above.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Template *corev1.PodTemplateSpec `json:"template,omitempty"`
+	// RestartPolicy of the trainer pods, Never by default.
+	// +kubebuilder:validation:Enum=Never;OnFailure
+	// +optional
+	RestartPolicy corev1.RestartPolicy `json:"restartPolicy,omitempty"`
+	// SuccessPolicy decides when the trainers have succeeded, AllTrainers
+	// by default.
+	// +optional
+	SuccessPolicy SuccessPolicy `json:"successPolicy,omitempty"`
+	// MinSucceeded is the number of trainers which have to succeed with the
+	// MinTrainers success policy, MinInstance by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinSucceeded int `json:"minSucceeded,omitempty"`
+	// MaxFailures is the number of trainer failures a fault tolerant job,
+	// i.e. a job with a master or elastic trainers, tolerates. A trainer
+	// failure fails any other job.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxFailures int `json:"maxFailures,omitempty"`
+	// ReplicaSpec is generated by the operator. It is left out of the
+	// schema, which would otherwise outgrow the size limits of the CRD.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	// +nullable
+	ReplicaSpec *batchv1.Job `json:"replicaSpec"`
+}
+
+// SuccessPolicy decides when the trainers of a job have succeeded. The
+// trainers are ranked by creation time, as in the replica statuses.
+// +kubebuilder:validation:Enum=AllTrainers;Trainer0;AnyTrainer;MinTrainers
+type SuccessPolicy string
+
+const (
+	// SuccessPolicyAllTrainers succeeds once all the trainers succeeded.
+	SuccessPolicyAllTrainers SuccessPolicy = "AllTrainers"
+	// SuccessPolicyTrainer0 succeeds once the trainer of rank 0, which
+	// usually saves the model, succeeded. A failure of the trainer of rank
+	// 0 fails the job whatever the tolerated failures.
+	SuccessPolicyTrainer0 SuccessPolicy = "Trainer0"
+	// SuccessPolicyAnyTrainer succeeds once any trainer succeeded.
+	SuccessPolicyAnyTrainer SuccessPolicy = "AnyTrainer"
+	// SuccessPolicyMinTrainers succeeds once MinSucceeded trainers
+	// succeeded.
+	SuccessPolicyMinTrainers SuccessPolicy = "MinTrainers"
+)
+
+// HeterSpec is the spec for the heterogeneous trainers in the paddle job
+type HeterSpec struct {
+	// +kubebuilder:validation:Minimum=1
+	Replicas int `json:"replicas"`
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources"`
+	// RoleContainer overrides the container of the role.
+	RoleContainer `json:",inline"`
+	// NodeSelector of the heter pods, it overrides the job wide one.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations of the heter pods.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Affinity of the heter pods.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// Template is the base of the heter pods, the container named "heter"
+	// is completed with the fields above.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Template *corev1.PodTemplateSpec `json:"template,omitempty"`
+	// ReplicaSpec is generated by the operator. It is left out of the
+	// schema, which would otherwise outgrow the size limits of the CRD.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	// +nullable
+	ReplicaSpec *v1beta1.ReplicaSet `json:"replicaSpec"`
+}
+
+// EvaluatorFailurePolicy is the action taken when the evaluator fails.
+// +kubebuilder:validation:Enum=Ignore;Fail
+type EvaluatorFailurePolicy string
+
+const (
+	// EvaluatorFailurePolicyIgnore lets the job go on, the evaluator is
+	// restarted.
+	EvaluatorFailurePolicyIgnore EvaluatorFailurePolicy = "Ignore"
+	// EvaluatorFailurePolicyFail fails the job.
+	EvaluatorFailurePolicyFail EvaluatorFailurePolicy = "Fail"
+)
+
+// EvaluatorSpec is the spec for the evaluator of the paddle job, a single
+// pod evaluating the checkpoints while the trainers train. It does not
+// count in the success of the job and is deleted when the trainers finish.
+type EvaluatorSpec struct {
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources"`
+	// RoleContainer overrides the container of the role, its command is
+	// required.
+	RoleContainer `json:",inline"`
+	// NodeSelector of the evaluator pod, it overrides the job wide one.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations of the evaluator pod.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Affinity of the evaluator pod.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// Template is the base of the evaluator pod, the container named
+	// "evaluator" is completed with the fields above.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Template *corev1.PodTemplateSpec `json:"template,omitempty"`
+	// FailurePolicy is applied when the evaluator fails, Ignore by default.
+	// +optional
+	FailurePolicy EvaluatorFailurePolicy `json:"failurePolicy,omitempty"`
+	// ReplicaSpec is generated by the operator. It is left out of the
+	// schema, which would otherwise outgrow the size limits of the CRD.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	// +nullable
+	ReplicaSpec *v1beta1.ReplicaSet `json:"replicaSpec"`
 }
 
 // PaddleJobPhase is the phase of PaddleJob
@@ -102,7 +397,7 @@ const (
 	PaddleJobPhaseFailed = "failed"
 )
 
-// TrainingResourceType the type of PaddleJob resource, include PSERVER and TRAINER
+// TrainingResourceType the type of PaddleJob resource, include PSERVER, TRAINER and HETER
 type TrainingResourceType string
 
 const (
@@ -110,6 +405,10 @@ const (
 	Pserver TrainingResourceType = "PSERVER"
 	// Trainer is the trainer name of TrainingResourceType.
 	Trainer TrainingResourceType = "TRAINER"
+	// Heter is the heterogeneous trainer name of TrainingResourceType.
+	Heter TrainingResourceType = "HETER"
+	// Evaluator is the evaluator name of TrainingResourceType.
+	Evaluator TrainingResourceType = "EVALUATOR"
 )
 
 // ResourceState is the state of a type of resource
@@ -118,6 +417,9 @@ type ResourceState string
 const (
 	// ResourceStateNone is the initial state of training job
 	ResourceStateNone ResourceState = ""
+	// ResourceStatePending is the pending state of ResourceState, the pod
+	// is not scheduled or its containers are not created yet.
+	ResourceStatePending = "pending"
 	// ResourceStateStarting is the starting state of ResourceState.
 	ResourceStateStarting = "starting"
 	// ResourceStateRunning is the  running state of ResourceState.
@@ -133,20 +435,282 @@ type TrainingResourceStatus struct {
 	// TrainingResourceType the type of PaddleJob resource, include PSERVER and TRAINER
 	TrainingResourceType `json:"training_resource_type"`
 	// State is the state of a type of resource
+	// +optional
 	State ResourceState `json:"state"`
 	// ResourceStates is the number of resource in different state
+	// +optional
+	// +nullable
 	ResourceStates map[ResourceState]int `json:"resource_states"`
+	// Pods is the status of every pod of the resource, ordered by rank.
+	// +optional
+	Pods []ReplicaPodStatus `json:"pods,omitempty"`
+}
+
+// ReplicaPodStatus is the status of a pod of a PaddleJob resource.
+type ReplicaPodStatus struct {
+	// Name of the pod.
+	Name string `json:"name"`
+	// NodeName is the node the pod is scheduled on.
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+	// Rank of the pod in the resource, pods are ranked by creation time.
+	Rank int `json:"rank"`
+	// Phase of the pod.
+	// +optional
+	Phase corev1.PodPhase `json:"phase,omitempty"`
+	// State of the pod.
+	// +optional
+	State ResourceState `json:"state,omitempty"`
+	// RestartCount of the container running the role.
+	// +optional
+	RestartCount int32 `json:"restartCount,omitempty"`
+	// LastExitCode is the exit code of the last termination of the
+	// container running the role.
+	// +optional
+	LastExitCode *int32 `json:"lastExitCode,omitempty"`
+}
+
+// FailureClass tells whether a failure is caused by the job or by the
+// cluster it runs on.
+// +kubebuilder:validation:Enum=User;Infrastructure
+type FailureClass string
+
+const (
+	// FailureClassUser is a failure of the job itself, retrying it
+	// without changing the job fails again.
+	FailureClassUser FailureClass = "User"
+	// FailureClassInfrastructure is a failure of the cluster, the job
+	// can be retried.
+	FailureClassInfrastructure FailureClass = "Infrastructure"
+)
+
+// FailureInfo describes the first failed pod of a PaddleJob.
+type FailureInfo struct {
+	// TrainingResourceType is the type of the resource of the failed pod.
+	TrainingResourceType `json:"training_resource_type"`
+	// Pod is the name of the failed pod.
+	Pod string `json:"pod"`
+	// Rank of the failed pod in its resource.
+	Rank int `json:"rank"`
+	// ExitCode of the container running the role.
+	// +optional
+	ExitCode *int32 `json:"exitCode,omitempty"`
+	// Reason of the failure, e.g. OOMKilled, Evicted or DeadlineExceeded.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message is the termination message of the container or the pod.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Log is the tail of the log of the container running the role.
+	// +optional
+	Log string `json:"log,omitempty"`
+	// Class tells whether the job can be retried.
+	Class FailureClass `json:"class"`
+	// Time the failure was observed.
+	// +optional
+	// +nullable
+	Time metav1.Time `json:"time,omitempty"`
 }
 
 // PaddleJobStatus is the status for a PaddleJob resource.
 type PaddleJobStatus struct {
 	// Phase is phase of PaddleJob
+	// +kubebuilder:validation:Enum="";creating;running;succeeded;failed
+	// +optional
 	Phase PaddleJobPhase `json:"phase"`
 	// Reason is the reason of job phase failed
+	// +optional
 	Reason string `json:"reason"`
-	// ReplicaStatuses is detail status of resources
-	// TODO(ZhengQi): should we only considered trainer job now?
+	// Trainers is the number of active trainers, it backs the scale subresource.
+	// +optional
+	Trainers int `json:"trainers,omitempty"`
+	// ReplicaStatuses is detail status of the pservers and the trainers
+	// +optional
+	// +nullable
 	ReplicaStatuses []*TrainingResourceStatus `json:"replica_statuses"`
+	// Failure describes the first pod of the job which failed.
+	// +optional
+	Failure *FailureInfo `json:"failure,omitempty"`
+	// PserverFailures are the last pserver failures and the action taken.
+	// +optional
+	PserverFailures []PserverFailure `json:"pserverFailures,omitempty"`
+	// Restarts is the number of times the job has been restarted.
+	// +optional
+	Restarts int `json:"restarts,omitempty"`
+	// PendingPods is the number of pods of the job which are not running yet.
+	// +optional
+	PendingPods int `json:"pendingPods,omitempty"`
+	// PendingReason summarizes why the pending pods are not running, e.g.
+	// they cannot be scheduled or their image cannot be pulled.
+	// +optional
+	PendingReason string `json:"pendingReason,omitempty"`
+	// Progress is the training progress reported by the trainers.
+	// +optional
+	Progress *TrainingProgress `json:"progress,omitempty"`
+	// Checkpoint is the latest checkpoint reported by the trainers.
+	// +optional
+	Checkpoint *CheckpointStatus `json:"checkpoint,omitempty"`
+	// Conditions are the current conditions of the job.
+	// +optional
+	Conditions []PaddleJobCondition `json:"conditions,omitempty"`
+}
+
+// PaddleJobConditionType is the type of a condition of a PaddleJob.
+type PaddleJobConditionType string
+
+const (
+	// PaddleJobDrifted tells that the replicasets or the trainer job of the
+	// job differ from the ones the operator created.
+	PaddleJobDrifted PaddleJobConditionType = "Drifted"
+)
+
+// PaddleJobCondition is a condition of a PaddleJob.
+type PaddleJobCondition struct {
+	// Type of the condition.
+	Type PaddleJobConditionType `json:"type"`
+	// Status of the condition, True, False or Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// Reason is a one word reason of the last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message details the last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastTransitionTime is when the status last changed.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// DriftPolicy is the action taken when the children of a job are changed
+// by hand.
+type DriftPolicy string
+
+const (
+	// DriftPolicyRevert overwrites the changes with the spec the operator
+	// created the children with.
+	DriftPolicyRevert DriftPolicy = "Revert"
+	// DriftPolicyFlag keeps the changes, the job gets the Drifted condition
+	// and a warning event.
+	DriftPolicyFlag DriftPolicy = "Flag"
+)
+
+// StallPolicy is the action taken when the trainers stop making progress.
+type StallPolicy string
+
+const (
+	// StallPolicyWarn records a warning event for the job.
+	StallPolicyWarn StallPolicy = "Warn"
+	// StallPolicyRestartJob restarts the pservers and the trainers.
+	StallPolicyRestartJob StallPolicy = "RestartJob"
+	// StallPolicyFail fails the job.
+	StallPolicyFail StallPolicy = "Fail"
+)
+
+// TrainingProgress is the progress of the slowest trainer of a job.
+type TrainingProgress struct {
+	// Pass is the current pass, starting from 0.
+	Pass int `json:"pass"`
+	// Batch is the number of batches done in the current pass.
+	Batch int `json:"batch"`
+	// Percent is the part of the passes of the job done.
+	Percent int `json:"percent"`
+	// Metrics are the last metrics reported, e.g. the loss.
+	// +optional
+	Metrics map[string]string `json:"metrics,omitempty"`
+	// StartTime is when the first progress was reported.
+	StartTime metav1.Time `json:"startTime"`
+	// LastUpdateTime is when the progress last advanced.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+	// EstimatedCompletionTime is when the job will complete at the speed
+	// observed since StartTime.
+	// +optional
+	EstimatedCompletionTime *metav1.Time `json:"estimatedCompletionTime,omitempty"`
+}
+
+// MasterSpec is the master of a fault tolerant job. It splits the chunks of
+// a dataset into tasks and leases them to the trainers pass after pass.
+type MasterSpec struct {
+	// Dataset is the path in the master pod of the manifest listing the
+	// chunks of the dataset, one per line. It is read from the volumes of
+	// the job.
+	Dataset string `json:"dataset"`
+	// ChunksPerTask is the number of chunks of a task, 1 by default.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ChunksPerTask int `json:"chunksPerTask,omitempty"`
+	// TaskTimeoutSeconds is how long a trainer may hold a task before it is
+	// given to another trainer, 600 by default.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TaskTimeoutSeconds int `json:"taskTimeoutSeconds,omitempty"`
+	// MaxTaskFailures is the number of times a task may fail in a pass
+	// before it is dropped, 3 by default.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxTaskFailures int `json:"maxTaskFailures,omitempty"`
+	// StatePath is the file the master persists its queue in. It is in the
+	// checkpoint directory if the job has one, the queue is not persisted
+	// otherwise.
+	// +optional
+	StatePath string `json:"statePath,omitempty"`
+	// Image is the image of the master, the one of the operator by
+	// default.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Resources are the resources of the master.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// CheckpointSpec is the volume and the directory the checkpoints of a job
+// are saved in.
+type CheckpointSpec struct {
+	// PersistentVolumeClaim is the claim of the checkpoint volume.
+	// +optional
+	PersistentVolumeClaim *corev1.PersistentVolumeClaimVolumeSource `json:"persistentVolumeClaim,omitempty"`
+	// HostPath is the checkpoint volume if no claim is set.
+	// +optional
+	HostPath *corev1.HostPathVolumeSource `json:"hostPath,omitempty"`
+	// Directory is the directory of the checkpoints in the volume, the name
+	// of the job by default.
+	// +optional
+	Directory string `json:"directory,omitempty"`
+	// IntervalSeconds is how often the job should save a checkpoint, it is
+	// passed to the pods as a hint.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	IntervalSeconds int `json:"intervalSeconds,omitempty"`
+	// Retention is the number of checkpoints kept, all of them if zero.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Retention int `json:"retention,omitempty"`
+}
+
+// CheckpointStatus is the latest checkpoint of a job and the checkpoints
+// kept.
+type CheckpointStatus struct {
+	// Latest is the path of the latest checkpoint in the checkpoint
+	// directory.
+	Latest string `json:"latest"`
+	// Pass is the pass of the latest checkpoint.
+	Pass int `json:"pass"`
+	// Time is when the latest checkpoint was reported.
+	Time metav1.Time `json:"time"`
+	// Retained are the checkpoints kept, the oldest first.
+	// +optional
+	Retained []string `json:"retained,omitempty"`
+}
+
+// PserverFailure is a failure of a running pserver.
+type PserverFailure struct {
+	// Pod is the name of the failed pserver pod.
+	Pod string `json:"pod"`
+	// Reason of the failure.
+	Reason string `json:"reason"`
+	// Action is the failure policy applied.
+	Action PserverFailurePolicy `json:"action"`
+	// Time the failure was observed.
+	Time metav1.Time `json:"time"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
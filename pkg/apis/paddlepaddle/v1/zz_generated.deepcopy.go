@@ -22,6 +22,7 @@ import (
 	batch_v1 "k8s.io/api/batch/v1"
 	core_v1 "k8s.io/api/core/v1"
 	v1beta1 "k8s.io/api/extensions/v1beta1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	reflect "reflect"
@@ -32,14 +33,58 @@ import (
 // Deprecated: deepcopy registration will go away when static deepcopy is fully implemented.
 func GetGeneratedDeepCopyFuncs() []conversion.GeneratedDeepCopyFunc {
 	return []conversion.GeneratedDeepCopyFunc{
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PserverFailure).DeepCopyInto(out.(*PserverFailure))
+			return nil
+		}, InType: reflect.TypeOf(&PserverFailure{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*PserverSpec).DeepCopyInto(out.(*PserverSpec))
 			return nil
 		}, InType: reflect.TypeOf(&PserverSpec{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ReplicaPodStatus).DeepCopyInto(out.(*ReplicaPodStatus))
+			return nil
+		}, InType: reflect.TypeOf(&ReplicaPodStatus{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RoleContainer).DeepCopyInto(out.(*RoleContainer))
+			return nil
+		}, InType: reflect.TypeOf(&RoleContainer{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*TrainerSpec).DeepCopyInto(out.(*TrainerSpec))
 			return nil
 		}, InType: reflect.TypeOf(&TrainerSpec{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*TrainingProgress).DeepCopyInto(out.(*TrainingProgress))
+			return nil
+		}, InType: reflect.TypeOf(&TrainingProgress{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*CheckpointSpec).DeepCopyInto(out.(*CheckpointSpec))
+			return nil
+		}, InType: reflect.TypeOf(&CheckpointSpec{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*CheckpointStatus).DeepCopyInto(out.(*CheckpointStatus))
+			return nil
+		}, InType: reflect.TypeOf(&CheckpointStatus{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*FailureInfo).DeepCopyInto(out.(*FailureInfo))
+			return nil
+		}, InType: reflect.TypeOf(&FailureInfo{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*MasterSpec).DeepCopyInto(out.(*MasterSpec))
+			return nil
+		}, InType: reflect.TypeOf(&MasterSpec{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*HeterSpec).DeepCopyInto(out.(*HeterSpec))
+			return nil
+		}, InType: reflect.TypeOf(&HeterSpec{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*EvaluatorSpec).DeepCopyInto(out.(*EvaluatorSpec))
+			return nil
+		}, InType: reflect.TypeOf(&EvaluatorSpec{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PaddleJobCondition).DeepCopyInto(out.(*PaddleJobCondition))
+			return nil
+		}, InType: reflect.TypeOf(&PaddleJobCondition{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*PaddleJob).DeepCopyInto(out.(*PaddleJob))
 			return nil
@@ -63,10 +108,60 @@ func GetGeneratedDeepCopyFuncs() []conversion.GeneratedDeepCopyFunc {
 	}
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PserverFailure) DeepCopyInto(out *PserverFailure) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PserverFailure.
+func (in *PserverFailure) DeepCopy() *PserverFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(PserverFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PserverSpec) DeepCopyInto(out *PserverSpec) {
 	*out = *in
 	in.Resources.DeepCopyInto(&out.Resources)
+	in.RoleContainer.DeepCopyInto(&out.RoleContainer)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]core_v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.Affinity)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.PodTemplateSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	if in.ReplicaSpec != nil {
 		in, out := &in.ReplicaSpec, &out.ReplicaSpec
 		if *in == nil {
@@ -89,10 +184,108 @@ func (in *PserverSpec) DeepCopy() *PserverSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaPodStatus) DeepCopyInto(out *ReplicaPodStatus) {
+	*out = *in
+	if in.LastExitCode != nil {
+		in, out := &in.LastExitCode, &out.LastExitCode
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaPodStatus.
+func (in *ReplicaPodStatus) DeepCopy() *ReplicaPodStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaPodStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleContainer) DeepCopyInto(out *RoleContainer) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]core_v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]core_v1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleContainer.
+func (in *RoleContainer) DeepCopy() *RoleContainer {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleContainer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrainerSpec) DeepCopyInto(out *TrainerSpec) {
 	*out = *in
 	in.Resources.DeepCopyInto(&out.Resources)
+	in.RoleContainer.DeepCopyInto(&out.RoleContainer)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]core_v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.Affinity)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.PodTemplateSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	if in.ReplicaSpec != nil {
 		in, out := &in.ReplicaSpec, &out.ReplicaSpec
 		if *in == nil {
@@ -115,6 +308,223 @@ func (in *TrainerSpec) DeepCopy() *TrainerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CheckpointSpec) DeepCopyInto(out *CheckpointSpec) {
+	*out = *in
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.PersistentVolumeClaimVolumeSource)
+			**out = **in
+		}
+	}
+	if in.HostPath != nil {
+		in, out := &in.HostPath, &out.HostPath
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.HostPathVolumeSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CheckpointSpec.
+func (in *CheckpointSpec) DeepCopy() *CheckpointSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CheckpointSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CheckpointStatus) DeepCopyInto(out *CheckpointStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Retained != nil {
+		in, out := &in.Retained, &out.Retained
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CheckpointStatus.
+func (in *CheckpointStatus) DeepCopy() *CheckpointStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CheckpointStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailureInfo) DeepCopyInto(out *FailureInfo) {
+	*out = *in
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailureInfo.
+func (in *FailureInfo) DeepCopy() *FailureInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(FailureInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeterSpec) DeepCopyInto(out *HeterSpec) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	in.RoleContainer.DeepCopyInto(&out.RoleContainer)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]core_v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.Affinity)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.PodTemplateSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.ReplicaSpec != nil {
+		in, out := &in.ReplicaSpec, &out.ReplicaSpec
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1beta1.ReplicaSet)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeterSpec.
+func (in *HeterSpec) DeepCopy() *HeterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HeterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvaluatorSpec) DeepCopyInto(out *EvaluatorSpec) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	in.RoleContainer.DeepCopyInto(&out.RoleContainer)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]core_v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.Affinity)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.PodTemplateSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.ReplicaSpec != nil {
+		in, out := &in.ReplicaSpec, &out.ReplicaSpec
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1beta1.ReplicaSet)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvaluatorSpec.
+func (in *EvaluatorSpec) DeepCopy() *EvaluatorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EvaluatorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MasterSpec) DeepCopyInto(out *MasterSpec) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MasterSpec.
+func (in *MasterSpec) DeepCopy() *MasterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MasterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PaddleJob) DeepCopyInto(out *PaddleJob) {
 	*out = *in
@@ -195,8 +605,65 @@ func (in *PaddleJobSpec) DeepCopyInto(out *PaddleJobSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]core_v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.StallTimeout != nil {
+		in, out := &in.StallTimeout, &out.StallTimeout
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	if in.Checkpoint != nil {
+		in, out := &in.Checkpoint, &out.Checkpoint
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(CheckpointSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Master != nil {
+		in, out := &in.Master, &out.Master
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(MasterSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	in.Pserver.DeepCopyInto(&out.Pserver)
 	in.Trainer.DeepCopyInto(&out.Trainer)
+	if in.Heter != nil {
+		in, out := &in.Heter, &out.Heter
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(HeterSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Evaluator != nil {
+		in, out := &in.Evaluator, &out.Evaluator
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(EvaluatorSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -210,6 +677,23 @@ func (in *PaddleJobSpec) DeepCopy() *PaddleJobSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PaddleJobCondition) DeepCopyInto(out *PaddleJobCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PaddleJobCondition.
+func (in *PaddleJobCondition) DeepCopy() *PaddleJobCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(PaddleJobCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PaddleJobStatus) DeepCopyInto(out *PaddleJobStatus) {
 	*out = *in
@@ -225,6 +709,47 @@ func (in *PaddleJobStatus) DeepCopyInto(out *PaddleJobStatus) {
 			}
 		}
 	}
+	if in.Failure != nil {
+		in, out := &in.Failure, &out.Failure
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(FailureInfo)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.PserverFailures != nil {
+		in, out := &in.PserverFailures, &out.PserverFailures
+		*out = make([]PserverFailure, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(TrainingProgress)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Checkpoint != nil {
+		in, out := &in.Checkpoint, &out.Checkpoint
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(CheckpointStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]PaddleJobCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -248,6 +773,13 @@ func (in *TrainingResourceStatus) DeepCopyInto(out *TrainingResourceStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]ReplicaPodStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -260,3 +792,36 @@ func (in *TrainingResourceStatus) DeepCopy() *TrainingResourceStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrainingProgress) DeepCopyInto(out *TrainingProgress) {
+	*out = *in
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	if in.EstimatedCompletionTime != nil {
+		in, out := &in.EstimatedCompletionTime, &out.EstimatedCompletionTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrainingProgress.
+func (in *TrainingProgress) DeepCopy() *TrainingProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(TrainingProgress)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,445 @@
+package v1beta2
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+const (
+	// entrypointEnv and workspaceEnv carry the v1 trainer entrypoint
+	// and workspace in the trainer container of v1beta2.
+	entrypointEnv = "ENTRY"
+	workspaceEnv  = "TRAINER_PACKAGE"
+
+	// conversionDataAnnotation keeps the fields of the v1 spec of a job
+	// read as v1beta2 which v1beta2 folds into the role templates, they
+	// are restored from it when the unchanged job is converted back.
+	conversionDataAnnotation = "paddlepaddle.org/v1-spec"
+)
+
+// ConvertTo converts this PaddleJob to the storage version v1.
+func (src *PaddleJob) ConvertTo(dst *v1.PaddleJob) error {
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	data, err := popConversionData(&dst.ObjectMeta)
+	if err != nil {
+		return err
+	}
+	dst.Spec.Port = src.Spec.Port
+	dst.Spec.PortsNum = src.Spec.PortsNum
+	dst.Spec.PortsNumForSparse = src.Spec.PortsNumForSparse
+	dst.Spec.Passes = src.Spec.Passes
+
+	for tp := range src.Spec.ReplicaSpecs {
+		if tp != ReplicaTypePserver && tp != ReplicaTypeTrainer && tp != ReplicaTypeHeter && tp != ReplicaTypeEvaluator {
+			return fmt.Errorf("replica type %v is not supported by %v", tp, v1.SchemeGroupVersion)
+		}
+	}
+
+	trainer := src.Spec.ReplicaSpecs[ReplicaTypeTrainer]
+	if trainer == nil {
+		return fmt.Errorf("replica spec of %v is missing", ReplicaTypeTrainer)
+	}
+	dst.Spec.Trainer.MinInstance, dst.Spec.Trainer.MaxInstance = replicasToV1(trainer)
+	dst.Spec.Trainer.RestartPolicy = trainer.RestartPolicy
+	dst.Spec.Trainer.SuccessPolicy = src.Spec.SuccessPolicy
+	dst.Spec.Trainer.MinSucceeded = src.Spec.MinSucceeded
+	dst.Spec.Trainer.MaxFailures = src.Spec.MaxTrainerFailures
+	dst.Spec.Trainer.Template = trainer.Template.DeepCopy()
+	// v1beta2 sets the host network on the pod templates of the roles.
+	dst.Spec.HostNetwork = trainer.Template.Spec.HostNetwork
+	c := v1.Container(&dst.Spec.Trainer.Template.Spec, string(ReplicaTypeTrainer))
+	dst.Spec.Trainer.Resources = *c.Resources.DeepCopy()
+	dst.Spec.Trainer.Entrypoint = popEnv(c, entrypointEnv)
+	dst.Spec.Trainer.Workspace = popEnv(c, workspaceEnv)
+	dst.Spec.Image = c.Image
+
+	dst.Spec.Pserver.FailurePolicy = src.Spec.PserverFailurePolicy
+	dst.Spec.Pserver.MaxRestarts = src.Spec.MaxRestarts
+	if src.Spec.StallTimeout != nil {
+		timeout := *src.Spec.StallTimeout
+		dst.Spec.StallTimeout = &timeout
+	}
+	dst.Spec.StallPolicy = src.Spec.StallPolicy
+	dst.Spec.Checkpoint = src.Spec.Checkpoint.DeepCopy()
+	dst.Spec.Master = src.Spec.Master.DeepCopy()
+	dst.Spec.WaitForPservers = src.Spec.WaitForPservers
+	dst.Spec.NetworkPolicy = src.Spec.NetworkPolicy
+	dst.Spec.DriftPolicy = src.Spec.DriftPolicy
+	if pserver := src.Spec.ReplicaSpecs[ReplicaTypePserver]; pserver != nil {
+		dst.Spec.Pserver.MinInstance, dst.Spec.Pserver.MaxInstance = replicasToV1(pserver)
+		dst.Spec.Pserver.Template = pserver.Template.DeepCopy()
+		c := v1.Container(&dst.Spec.Pserver.Template.Spec, string(ReplicaTypePserver))
+		dst.Spec.Pserver.Resources = *c.Resources.DeepCopy()
+	}
+	if heter := src.Spec.ReplicaSpecs[ReplicaTypeHeter]; heter != nil {
+		dst.Spec.Heter = &v1.HeterSpec{Template: heter.Template.DeepCopy()}
+		dst.Spec.Heter.Replicas, _ = replicasToV1(heter)
+		c := v1.Container(&dst.Spec.Heter.Template.Spec, string(ReplicaTypeHeter))
+		dst.Spec.Heter.Resources = *c.Resources.DeepCopy()
+	}
+	if evaluator := src.Spec.ReplicaSpecs[ReplicaTypeEvaluator]; evaluator != nil {
+		dst.Spec.Evaluator = &v1.EvaluatorSpec{
+			Template:      evaluator.Template.DeepCopy(),
+			FailurePolicy: src.Spec.EvaluatorFailurePolicy,
+		}
+		c := v1.Container(&dst.Spec.Evaluator.Template.Spec, string(ReplicaTypeEvaluator))
+		dst.Spec.Evaluator.Resources = *c.Resources.DeepCopy()
+	}
+
+	dst.Status.Phase = src.Status.Phase
+	dst.Status.Reason = src.Status.Reason
+	dst.Status.Trainers = src.Status.Trainers
+	dst.Status.ReplicaStatuses = copyReplicaStatuses(src.Status.ReplicaStatuses)
+	dst.Status.Failure = src.Status.Failure.DeepCopy()
+	dst.Status.PserverFailures = copyPserverFailures(src.Status.PserverFailures)
+	dst.Status.Restarts = src.Status.Restarts
+	dst.Status.PendingPods = src.Status.PendingPods
+	dst.Status.PendingReason = src.Status.PendingReason
+	dst.Status.Progress = src.Status.Progress.DeepCopy()
+	dst.Status.Checkpoint = src.Status.Checkpoint.DeepCopy()
+	dst.Status.Conditions = copyConditions(src.Status.Conditions)
+	return restoreSpec(src, dst, data)
+}
+
+// ConvertFrom converts a PaddleJob of the storage version v1 to this version.
+func (dst *PaddleJob) ConvertFrom(src *v1.PaddleJob) error {
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	data, err := json.Marshal(foldedSpec(&src.Spec))
+	if err != nil {
+		return err
+	}
+	if dst.Annotations == nil {
+		dst.Annotations = map[string]string{}
+	}
+	dst.Annotations[conversionDataAnnotation] = string(data)
+	dst.Spec.Port = src.Spec.Port
+	dst.Spec.PortsNum = src.Spec.PortsNum
+	dst.Spec.PortsNumForSparse = src.Spec.PortsNumForSparse
+	dst.Spec.Passes = src.Spec.Passes
+	dst.Spec.PserverFailurePolicy = src.Spec.Pserver.FailurePolicy
+	dst.Spec.MaxRestarts = src.Spec.Pserver.MaxRestarts
+	if src.Spec.StallTimeout != nil {
+		timeout := *src.Spec.StallTimeout
+		dst.Spec.StallTimeout = &timeout
+	}
+	dst.Spec.StallPolicy = src.Spec.StallPolicy
+	dst.Spec.Checkpoint = src.Spec.Checkpoint.DeepCopy()
+	dst.Spec.Master = src.Spec.Master.DeepCopy()
+	dst.Spec.WaitForPservers = src.Spec.WaitForPservers
+	dst.Spec.NetworkPolicy = src.Spec.NetworkPolicy
+	dst.Spec.DriftPolicy = src.Spec.DriftPolicy
+	dst.Spec.SuccessPolicy = src.Spec.Trainer.SuccessPolicy
+	dst.Spec.MinSucceeded = src.Spec.Trainer.MinSucceeded
+	dst.Spec.MaxTrainerFailures = src.Spec.Trainer.MaxFailures
+
+	pserver := &ReplicaSpec{
+		Template: roleTemplate(src, src.Spec.Pserver.Template, string(ReplicaTypePserver), &src.Spec.Pserver.Resources),
+	}
+	pserver.Replicas, pserver.MaxReplicas = replicasFromV1(src.Spec.Pserver.MinInstance, src.Spec.Pserver.MaxInstance)
+	setScheduling(&pserver.Template.Spec, src.Spec.Pserver.NodeSelector, src.Spec.Pserver.Tolerations, src.Spec.Pserver.Affinity)
+	src.Spec.Pserver.RoleContainer.ApplyTo(v1.Container(&pserver.Template.Spec, string(ReplicaTypePserver)))
+
+	trainer := &ReplicaSpec{
+		RestartPolicy: src.Spec.Trainer.RestartPolicy,
+		Template:      roleTemplate(src, src.Spec.Trainer.Template, string(ReplicaTypeTrainer), &src.Spec.Trainer.Resources),
+	}
+	trainer.Replicas, trainer.MaxReplicas = replicasFromV1(src.Spec.Trainer.MinInstance, src.Spec.Trainer.MaxInstance)
+	setScheduling(&trainer.Template.Spec, src.Spec.Trainer.NodeSelector, src.Spec.Trainer.Tolerations, src.Spec.Trainer.Affinity)
+	c := v1.Container(&trainer.Template.Spec, string(ReplicaTypeTrainer))
+	src.Spec.Trainer.RoleContainer.ApplyTo(c)
+	c.VolumeMounts = append(c.VolumeMounts, src.Spec.VolumeMounts...)
+	if src.Spec.Trainer.Entrypoint != "" {
+		c.Env = append(c.Env, corev1.EnvVar{Name: entrypointEnv, Value: src.Spec.Trainer.Entrypoint})
+	}
+	if src.Spec.Trainer.Workspace != "" {
+		c.Env = append(c.Env, corev1.EnvVar{Name: workspaceEnv, Value: src.Spec.Trainer.Workspace})
+	}
+
+	dst.Spec.ReplicaSpecs = map[ReplicaType]*ReplicaSpec{
+		ReplicaTypePserver: pserver,
+		ReplicaTypeTrainer: trainer,
+	}
+	if src.Spec.Heter != nil {
+		heter := &ReplicaSpec{
+			Template: roleTemplate(src, src.Spec.Heter.Template, string(ReplicaTypeHeter), &src.Spec.Heter.Resources),
+		}
+		heter.Replicas, _ = replicasFromV1(src.Spec.Heter.Replicas, 0)
+		setScheduling(&heter.Template.Spec, src.Spec.Heter.NodeSelector, src.Spec.Heter.Tolerations, src.Spec.Heter.Affinity)
+		src.Spec.Heter.RoleContainer.ApplyTo(v1.Container(&heter.Template.Spec, string(ReplicaTypeHeter)))
+		dst.Spec.ReplicaSpecs[ReplicaTypeHeter] = heter
+	}
+	if src.Spec.Evaluator != nil {
+		evaluator := &ReplicaSpec{
+			Template: roleTemplate(src, src.Spec.Evaluator.Template, string(ReplicaTypeEvaluator), &src.Spec.Evaluator.Resources),
+		}
+		evaluator.Replicas, _ = replicasFromV1(1, 0)
+		setScheduling(&evaluator.Template.Spec, src.Spec.Evaluator.NodeSelector, src.Spec.Evaluator.Tolerations, src.Spec.Evaluator.Affinity)
+		src.Spec.Evaluator.RoleContainer.ApplyTo(v1.Container(&evaluator.Template.Spec, string(ReplicaTypeEvaluator)))
+		dst.Spec.ReplicaSpecs[ReplicaTypeEvaluator] = evaluator
+		dst.Spec.EvaluatorFailurePolicy = src.Spec.Evaluator.FailurePolicy
+	}
+
+	dst.Status.Phase = src.Status.Phase
+	dst.Status.Reason = src.Status.Reason
+	dst.Status.Trainers = src.Status.Trainers
+	dst.Status.ReplicaStatuses = copyReplicaStatuses(src.Status.ReplicaStatuses)
+	dst.Status.Failure = src.Status.Failure.DeepCopy()
+	dst.Status.PserverFailures = copyPserverFailures(src.Status.PserverFailures)
+	dst.Status.Restarts = src.Status.Restarts
+	dst.Status.PendingPods = src.Status.PendingPods
+	dst.Status.PendingReason = src.Status.PendingReason
+	dst.Status.Progress = src.Status.Progress.DeepCopy()
+	dst.Status.Checkpoint = src.Status.Checkpoint.DeepCopy()
+	dst.Status.Conditions = copyConditions(src.Status.Conditions)
+	return nil
+}
+
+// roleTemplate builds the v1beta2 pod template of a role from the v1
+// template and the job wide fields of src.
+func roleTemplate(src *v1.PaddleJob, template *corev1.PodTemplateSpec, role string,
+	resources *corev1.ResourceRequirements) corev1.PodTemplateSpec {
+	t := corev1.PodTemplateSpec{}
+	if template != nil {
+		template.DeepCopyInto(&t)
+	}
+	c := v1.Container(&t.Spec, role)
+	if c.Image == "" {
+		c.Image = src.Spec.Image
+	}
+	if len(c.Resources.Requests) == 0 && len(c.Resources.Limits) == 0 {
+		resources.DeepCopyInto(&c.Resources)
+	}
+	for _, v := range src.Spec.Volumes {
+		t.Spec.Volumes = append(t.Spec.Volumes, *v.DeepCopy())
+	}
+	if len(t.Spec.NodeSelector) == 0 && len(src.Spec.NodeSelector) > 0 {
+		t.Spec.NodeSelector = make(map[string]string, len(src.Spec.NodeSelector))
+		for k, v := range src.Spec.NodeSelector {
+			t.Spec.NodeSelector[k] = v
+		}
+	}
+	t.Spec.HostNetwork = t.Spec.HostNetwork || src.Spec.HostNetwork
+	t.Spec.ImagePullSecrets = append(t.Spec.ImagePullSecrets, src.Spec.ImagePullSecrets...)
+	if t.Spec.ServiceAccountName == "" {
+		t.Spec.ServiceAccountName = src.Spec.ServiceAccountName
+	}
+	return t
+}
+
+// setScheduling folds the v1 scheduling fields of a role into spec, they
+// take precedence over the template and the job wide node selector.
+func setScheduling(spec *corev1.PodSpec, nodeSelector map[string]string,
+	tolerations []corev1.Toleration, affinity *corev1.Affinity) {
+	if len(nodeSelector) > 0 {
+		spec.NodeSelector = make(map[string]string, len(nodeSelector))
+		for k, v := range nodeSelector {
+			spec.NodeSelector[k] = v
+		}
+	}
+	for _, t := range tolerations {
+		spec.Tolerations = append(spec.Tolerations, *t.DeepCopy())
+	}
+	if affinity != nil {
+		spec.Affinity = affinity.DeepCopy()
+	}
+}
+
+// popEnv removes the environment variable name from c and returns its value.
+func popEnv(c *corev1.Container, name string) string {
+	for i, e := range c.Env {
+		if e.Name == name && e.ValueFrom == nil {
+			c.Env = append(c.Env[:i], c.Env[i+1:]...)
+			return e.Value
+		}
+	}
+	return ""
+}
+
+// popConversionData removes the folded v1 fields kept by ConvertFrom from
+// meta and returns them, or nil if meta has none.
+func popConversionData(meta *metav1.ObjectMeta) (*v1.PaddleJobSpec, error) {
+	raw, ok := meta.Annotations[conversionDataAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	delete(meta.Annotations, conversionDataAnnotation)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+	data := &v1.PaddleJobSpec{}
+	if err := json.Unmarshal([]byte(raw), data); err != nil {
+		return nil, fmt.Errorf("decode annotation %v error: %v", conversionDataAnnotation, err)
+	}
+	return data, nil
+}
+
+// foldedSpec returns the fields of spec which ConvertFrom folds into the role
+// templates, v1beta2 cannot tell them apart from the templates. The other
+// fields, including the replica specs generated by the operator, are left
+// out to keep the annotation small.
+func foldedSpec(spec *v1.PaddleJobSpec) *v1.PaddleJobSpec {
+	f := &v1.PaddleJobSpec{
+		Image:              spec.Image,
+		HostNetwork:        spec.HostNetwork,
+		Volumes:            spec.Volumes,
+		VolumeMounts:       spec.VolumeMounts,
+		NodeSelector:       spec.NodeSelector,
+		ImagePullSecrets:   spec.ImagePullSecrets,
+		ServiceAccountName: spec.ServiceAccountName,
+	}
+	f.Pserver.Resources = spec.Pserver.Resources
+	f.Pserver.RoleContainer = spec.Pserver.RoleContainer
+	f.Pserver.NodeSelector = spec.Pserver.NodeSelector
+	f.Pserver.Tolerations = spec.Pserver.Tolerations
+	f.Pserver.Affinity = spec.Pserver.Affinity
+	f.Pserver.Template = spec.Pserver.Template
+	f.Trainer.Entrypoint = spec.Trainer.Entrypoint
+	f.Trainer.Workspace = spec.Trainer.Workspace
+	f.Trainer.Resources = spec.Trainer.Resources
+	f.Trainer.RoleContainer = spec.Trainer.RoleContainer
+	f.Trainer.NodeSelector = spec.Trainer.NodeSelector
+	f.Trainer.Tolerations = spec.Trainer.Tolerations
+	f.Trainer.Affinity = spec.Trainer.Affinity
+	f.Trainer.Template = spec.Trainer.Template
+	if h := spec.Heter; h != nil {
+		f.Heter = &v1.HeterSpec{
+			Resources:     h.Resources,
+			RoleContainer: h.RoleContainer,
+			NodeSelector:  h.NodeSelector,
+			Tolerations:   h.Tolerations,
+			Affinity:      h.Affinity,
+			Template:      h.Template,
+		}
+	}
+	if e := spec.Evaluator; e != nil {
+		f.Evaluator = &v1.EvaluatorSpec{
+			Resources:     e.Resources,
+			RoleContainer: e.RoleContainer,
+			NodeSelector:  e.NodeSelector,
+			Tolerations:   e.Tolerations,
+			Affinity:      e.Affinity,
+			Template:      e.Template,
+		}
+	}
+	return f.DeepCopy()
+}
+
+// unfold sets the fields of spec which ConvertFrom folds into the role
+// templates to the ones of f.
+func unfold(spec *v1.PaddleJobSpec, f *v1.PaddleJobSpec) {
+	spec.Image = f.Image
+	spec.HostNetwork = f.HostNetwork
+	spec.Volumes = f.Volumes
+	spec.VolumeMounts = f.VolumeMounts
+	spec.NodeSelector = f.NodeSelector
+	spec.ImagePullSecrets = f.ImagePullSecrets
+	spec.ServiceAccountName = f.ServiceAccountName
+	spec.Pserver.Resources = f.Pserver.Resources
+	spec.Pserver.RoleContainer = f.Pserver.RoleContainer
+	spec.Pserver.NodeSelector = f.Pserver.NodeSelector
+	spec.Pserver.Tolerations = f.Pserver.Tolerations
+	spec.Pserver.Affinity = f.Pserver.Affinity
+	spec.Pserver.Template = f.Pserver.Template
+	spec.Trainer.Entrypoint = f.Trainer.Entrypoint
+	spec.Trainer.Workspace = f.Trainer.Workspace
+	spec.Trainer.Resources = f.Trainer.Resources
+	spec.Trainer.RoleContainer = f.Trainer.RoleContainer
+	spec.Trainer.NodeSelector = f.Trainer.NodeSelector
+	spec.Trainer.Tolerations = f.Trainer.Tolerations
+	spec.Trainer.Affinity = f.Trainer.Affinity
+	spec.Trainer.Template = f.Trainer.Template
+	if h := spec.Heter; h != nil && f.Heter != nil {
+		h.Resources = f.Heter.Resources
+		h.RoleContainer = f.Heter.RoleContainer
+		h.NodeSelector = f.Heter.NodeSelector
+		h.Tolerations = f.Heter.Tolerations
+		h.Affinity = f.Heter.Affinity
+		h.Template = f.Heter.Template
+	}
+	if e := spec.Evaluator; e != nil && f.Evaluator != nil {
+		e.Resources = f.Evaluator.Resources
+		e.RoleContainer = f.Evaluator.RoleContainer
+		e.NodeSelector = f.Evaluator.NodeSelector
+		e.Tolerations = f.Evaluator.Tolerations
+		e.Affinity = f.Evaluator.Affinity
+		e.Template = f.Evaluator.Template
+	}
+}
+
+// restoreSpec restores the fields of the spec of dst converted from src
+// which ConvertFrom folded into the role templates to the ones kept in data,
+// if src was not changed since.
+func restoreSpec(src *PaddleJob, dst *v1.PaddleJob, data *v1.PaddleJobSpec) error {
+	if data == nil {
+		return nil
+	}
+	restored := dst.Spec.DeepCopy()
+	unfold(restored, data)
+	converted := &PaddleJob{}
+	if err := converted.ConvertFrom(&v1.PaddleJob{Spec: *restored}); err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(converted.Spec, src.Spec) {
+		dst.Spec = *restored
+	}
+	return nil
+}
+
+func replicasToV1(r *ReplicaSpec) (min, max int) {
+	if r.Replicas != nil {
+		min = int(*r.Replicas)
+	}
+	max = min
+	if r.MaxReplicas != nil {
+		max = int(*r.MaxReplicas)
+	}
+	return min, max
+}
+
+func replicasFromV1(min, max int) (*int32, *int32) {
+	replicas := int32(min)
+	if max == 0 || max == min {
+		return &replicas, nil
+	}
+	maxReplicas := int32(max)
+	return &replicas, &maxReplicas
+}
+
+func copyReplicaStatuses(in []*v1.TrainingResourceStatus) []*v1.TrainingResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := make([]*v1.TrainingResourceStatus, len(in))
+	for i := range in {
+		out[i] = in[i].DeepCopy()
+	}
+	return out
+}
+
+func copyPserverFailures(in []v1.PserverFailure) []v1.PserverFailure {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1.PserverFailure, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
+
+func copyConditions(in []v1.PaddleJobCondition) []v1.PaddleJobCondition {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1.PaddleJobCondition, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
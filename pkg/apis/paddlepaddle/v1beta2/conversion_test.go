@@ -0,0 +1,175 @@
+package v1beta2
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	v1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+// testJob returns a v1 job setting the fields v1beta2 folds into the role
+// templates or has no place for.
+func testJob() *v1.PaddleJob {
+	src := &v1.PaddleJob{}
+	src.Name = "job-1"
+	src.Annotations = map[string]string{"owner": "paddle"}
+	src.Spec.Image = "paddlepaddle/paddlecloud-job"
+	src.Spec.Port = 7164
+	src.Spec.HostNetwork = true
+	src.Spec.NodeSelector = map[string]string{"pool": "paddle"}
+	src.Spec.Volumes = []corev1.Volume{{Name: "data"}}
+	src.Spec.VolumeMounts = []corev1.VolumeMount{{Name: "data", MountPath: "/data"}}
+	src.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+	src.Spec.ServiceAccountName = "trainer"
+	src.Spec.Pserver.MinInstance = 2
+	src.Spec.Pserver.MaxInstance = 2
+	src.Spec.Pserver.ReplicaSpec = &v1beta1.ReplicaSet{}
+	src.Spec.Pserver.ReplicaSpec.Name = "job-1-pserver"
+	src.Spec.Trainer.MinInstance = 2
+	src.Spec.Trainer.MaxInstance = 6
+	src.Spec.Trainer.Entrypoint = "python train.py"
+	src.Spec.Trainer.SuccessPolicy = v1.SuccessPolicyTrainer0
+	src.Spec.Trainer.Resources.Requests = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}
+	src.Spec.Trainer.Env = []corev1.EnvVar{{Name: "GLOG_v", Value: "3"}}
+	src.Spec.Trainer.Template = &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Tolerations: []corev1.Toleration{{Key: "gpu", Operator: corev1.TolerationOpExists}},
+		},
+	}
+	src.Spec.Trainer.ReplicaSpec = &batchv1.Job{}
+	src.Spec.Trainer.ReplicaSpec.Name = "job-1-trainer"
+	src.Spec.Heter = &v1.HeterSpec{Replicas: 2, NodeSelector: map[string]string{"pool": "gpu"}}
+	src.Spec.Evaluator = &v1.EvaluatorSpec{FailurePolicy: v1.EvaluatorFailurePolicyFail}
+	src.Spec.Evaluator.Command = []string{"python", "eval.py"}
+	src.Status.Phase = v1.PaddleJobPhaseRunning
+	src.Status.Trainers = 2
+	src.Status.Restarts = 1
+	return src
+}
+
+func TestConvertFrom(t *testing.T) {
+	src := testJob()
+	job := &PaddleJob{}
+	assert.Nil(t, job.ConvertFrom(src))
+	trainer := job.Spec.ReplicaSpecs[ReplicaTypeTrainer]
+	assert.Equal(t, int32(2), *trainer.Replicas)
+	assert.Equal(t, int32(6), *trainer.MaxReplicas)
+	assert.Nil(t, job.Spec.ReplicaSpecs[ReplicaTypePserver].MaxReplicas)
+	assert.Equal(t, "gpu", trainer.Template.Spec.Tolerations[0].Key)
+	assert.Equal(t, "paddle", trainer.Template.Spec.NodeSelector["pool"])
+	assert.True(t, trainer.Template.Spec.HostNetwork)
+	assert.Equal(t, src.Spec.Image, trainer.Template.Spec.Containers[0].Image)
+	heter := job.Spec.ReplicaSpecs[ReplicaTypeHeter]
+	assert.Equal(t, int32(2), *heter.Replicas)
+	assert.Equal(t, "gpu", heter.Template.Spec.NodeSelector["pool"])
+	evaluator := job.Spec.ReplicaSpecs[ReplicaTypeEvaluator]
+	assert.Equal(t, int32(1), *evaluator.Replicas)
+	assert.Equal(t, []string{"python", "eval.py"}, evaluator.Template.Spec.Containers[0].Command)
+	assert.Contains(t, job.Annotations, conversionDataAnnotation)
+	// The generated replica specs are not kept.
+	assert.NotContains(t, job.Annotations[conversionDataAnnotation], "job-1-trainer")
+	assert.Equal(t, "paddle", job.Annotations["owner"])
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	src := testJob()
+	job := &PaddleJob{}
+	assert.Nil(t, job.ConvertFrom(src))
+	// The job goes through the API server as JSON.
+	data, err := json.Marshal(job)
+	assert.Nil(t, err)
+	job = &PaddleJob{}
+	assert.Nil(t, json.Unmarshal(data, job))
+
+	dst := &v1.PaddleJob{}
+	assert.Nil(t, job.ConvertTo(dst))
+	// v1beta2 has no place for the replica specs, the operator generates
+	// them again.
+	expected := src.Spec.DeepCopy()
+	expected.Pserver.ReplicaSpec = nil
+	expected.Trainer.ReplicaSpec = nil
+	assert.True(t, equality.Semantic.DeepEqual(*expected, dst.Spec), "spec %v differs from %v", dst.Spec, *expected)
+	assert.Equal(t, src.ObjectMeta, dst.ObjectMeta)
+	assert.Equal(t, src.Status, dst.Status)
+
+	// A job without annotations gets none back.
+	src.Annotations = nil
+	job = &PaddleJob{}
+	assert.Nil(t, job.ConvertFrom(src))
+	dst = &v1.PaddleJob{}
+	assert.Nil(t, job.ConvertTo(dst))
+	assert.Nil(t, dst.Annotations)
+}
+
+func TestConvertToChanged(t *testing.T) {
+	src := testJob()
+	job := &PaddleJob{}
+	assert.Nil(t, job.ConvertFrom(src))
+	replicas := int32(4)
+	job.Spec.ReplicaSpecs[ReplicaTypeTrainer].Replicas = &replicas
+
+	dst := &v1.PaddleJob{}
+	assert.Nil(t, job.ConvertTo(dst))
+	assert.NotContains(t, dst.Annotations, conversionDataAnnotation)
+	assert.Equal(t, src.Spec.Image, dst.Spec.Image)
+	assert.True(t, dst.Spec.HostNetwork)
+	assert.Equal(t, 2, dst.Spec.Pserver.MaxInstance)
+	assert.Equal(t, 4, dst.Spec.Trainer.MinInstance)
+	assert.Equal(t, 6, dst.Spec.Trainer.MaxInstance)
+	assert.Equal(t, "python train.py", dst.Spec.Trainer.Entrypoint)
+	assert.Equal(t, v1.SuccessPolicyTrainer0, dst.Spec.Trainer.SuccessPolicy)
+	assert.Equal(t, []corev1.EnvVar{{Name: "GLOG_v", Value: "3"}}, dst.Spec.Trainer.Template.Spec.Containers[0].Env)
+	assert.Equal(t, int64(1), dst.Spec.Trainer.Resources.Requests.Cpu().Value())
+	assert.Equal(t, 2, dst.Spec.Heter.Replicas)
+	assert.Equal(t, "heter", dst.Spec.Heter.Template.Spec.Containers[0].Name)
+	assert.Equal(t, v1.EvaluatorFailurePolicyFail, dst.Spec.Evaluator.FailurePolicy)
+	// The folded fields stay in the templates of the changed job.
+	assert.Equal(t, "paddle", dst.Spec.Trainer.Template.Spec.NodeSelector["pool"])
+	assert.Nil(t, dst.Spec.NodeSelector)
+	assert.Nil(t, dst.Spec.Trainer.ReplicaSpec)
+}
+
+func TestConvertToWithoutConversionData(t *testing.T) {
+	replicas := int32(2)
+	job := &PaddleJob{}
+	job.Spec.ReplicaSpecs = map[ReplicaType]*ReplicaSpec{
+		ReplicaTypeTrainer: {
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					HostNetwork: true,
+					Containers: []corev1.Container{{
+						Name:  "trainer",
+						Image: "paddlepaddle/paddlecloud-job",
+						Env:   []corev1.EnvVar{{Name: entrypointEnv, Value: "python train.py"}},
+					}},
+				},
+			},
+		},
+	}
+
+	dst := &v1.PaddleJob{}
+	assert.Nil(t, job.ConvertTo(dst))
+	assert.True(t, dst.Spec.HostNetwork)
+	assert.Equal(t, "paddlepaddle/paddlecloud-job", dst.Spec.Image)
+	assert.Equal(t, "python train.py", dst.Spec.Trainer.Entrypoint)
+	assert.Empty(t, dst.Spec.Trainer.Template.Spec.Containers[0].Env)
+	assert.Nil(t, dst.Spec.Trainer.ReplicaSpec)
+}
+
+func TestConvertUnsupportedReplicaType(t *testing.T) {
+	replicas := int32(1)
+	job := &PaddleJob{}
+	job.Spec.ReplicaSpecs = map[ReplicaType]*ReplicaSpec{
+		ReplicaTypeTrainer: {Replicas: &replicas},
+		"master":           {Replicas: &replicas},
+	}
+	assert.NotNil(t, job.ConvertTo(&v1.PaddleJob{}))
+}
@@ -0,0 +1,202 @@
+package v1beta2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +resource:path=PaddleJob
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=paddlejobs,singular=paddlejob,shortName=tj
+// +kubebuilder:unservedversion
+// +kubebuilder:subresource:scale:specpath=.spec.replicaSpecs.trainer.replicas,statuspath=.status.trainers
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Trainers",type=integer,JSONPath=`.spec.replicaSpecs.trainer.replicas`
+// +kubebuilder:printcolumn:name="Pservers",type=integer,JSONPath=`.spec.replicaSpecs.pserver.replicas`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// PaddleJob is a specification for a PaddleJob resource
+type PaddleJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              PaddleJobSpec `json:"spec"`
+	// +optional
+	Status PaddleJobStatus `json:"status"`
+}
+
+// PaddleJobSpec is the spec for a PaddleJob resource
+type PaddleJobSpec struct {
+	// Port is the first of the ports used by the job.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int `json:"port,omitempty"`
+	// PortsNum is the number of ports used for dense parameters.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PortsNum int `json:"portsNum,omitempty"`
+	// PortsNumForSparse is the number of ports used for sparse parameters.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PortsNumForSparse int `json:"portsNumForSparse,omitempty"`
+	// Passes is the number of passes to train.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Passes int `json:"passes,omitempty"`
+	// ReplicaSpecs is the spec of every role of the job.
+	ReplicaSpecs map[ReplicaType]*ReplicaSpec `json:"replicaSpecs"`
+	// PserverFailurePolicy is applied when a running pserver crashes,
+	// restarts or is lost.
+	// +optional
+	PserverFailurePolicy v1.PserverFailurePolicy `json:"pserverFailurePolicy,omitempty"`
+	// MaxRestarts is the number of times the RestartJob policy restarts the
+	// job before failing it.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRestarts int `json:"maxRestarts,omitempty"`
+	// StallTimeout is how long the trainers may report no progress before
+	// StallPolicy is applied. The stall detection is disabled if it is unset.
+	// +optional
+	StallTimeout *metav1.Duration `json:"stallTimeout,omitempty"`
+	// StallPolicy is applied when the trainers stall, Warn by default.
+	// +kubebuilder:validation:Enum=Warn;RestartJob;Fail
+	// +optional
+	StallPolicy v1.StallPolicy `json:"stallPolicy,omitempty"`
+	// Checkpoint is where the pservers and the trainers save checkpoints.
+	// +optional
+	Checkpoint *v1.CheckpointSpec `json:"checkpoint,omitempty"`
+	// Master hands out the tasks of a dataset to the trainers of a fault
+	// tolerant job.
+	// +optional
+	Master *v1.MasterSpec `json:"master,omitempty"`
+	// WaitForPservers adds an init container to the trainers which blocks
+	// them until all the pservers are listed in the endpoints ConfigMap of
+	// the job and reachable.
+	// +optional
+	WaitForPservers bool `json:"waitForPservers,omitempty"`
+	// NetworkPolicy isolates the pods of the job, only the pods of the job
+	// and the operator may connect to them.
+	// +optional
+	NetworkPolicy bool `json:"networkPolicy,omitempty"`
+	// EvaluatorFailurePolicy is applied when the evaluator fails, Ignore by
+	// default.
+	// +optional
+	EvaluatorFailurePolicy v1.EvaluatorFailurePolicy `json:"evaluatorFailurePolicy,omitempty"`
+	// SuccessPolicy decides when the trainers have succeeded, AllTrainers
+	// by default.
+	// +kubebuilder:validation:Enum=AllTrainers;Trainer0;AnyTrainer;MinTrainers
+	// +optional
+	SuccessPolicy v1.SuccessPolicy `json:"successPolicy,omitempty"`
+	// MinSucceeded is the number of trainers which have to succeed with the
+	// MinTrainers success policy, the trainer replicas by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinSucceeded int `json:"minSucceeded,omitempty"`
+	// MaxTrainerFailures is the number of trainer failures a fault tolerant
+	// job tolerates.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxTrainerFailures int `json:"maxTrainerFailures,omitempty"`
+	// DriftPolicy is applied when the generated resources of the job are
+	// changed by hand, Revert by default.
+	// +kubebuilder:validation:Enum=Revert;Flag
+	// +optional
+	DriftPolicy v1.DriftPolicy `json:"driftPolicy,omitempty"`
+}
+
+// ReplicaType is the role of a replica in a PaddleJob.
+// +kubebuilder:validation:Enum=pserver;trainer;heter;evaluator
+type ReplicaType string
+
+const (
+	// ReplicaTypePserver is the parameter server role.
+	ReplicaTypePserver ReplicaType = "pserver"
+	// ReplicaTypeTrainer is the trainer role.
+	ReplicaTypeTrainer ReplicaType = "trainer"
+	// ReplicaTypeHeter is the heterogeneous trainer role.
+	ReplicaTypeHeter ReplicaType = "heter"
+	// ReplicaTypeEvaluator is the evaluator role, it has a single replica.
+	ReplicaTypeEvaluator ReplicaType = "evaluator"
+)
+
+// ReplicaSpec is the spec of the replicas of a role.
+type ReplicaSpec struct {
+	// Replicas is the desired number of replicas.
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas"`
+	// MaxReplicas is the upper bound of replicas in fault tolerant mode,
+	// it equals to Replicas if unset.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+	// RestartPolicy of the pods, pservers always restart and trainers
+	// never restart by default.
+	// +optional
+	RestartPolicy corev1.RestartPolicy `json:"restartPolicy,omitempty"`
+	// Template is the pod template of the role. The container named after
+	// the role runs the role, the operator adds the distributed training
+	// environment to it. The API server validates it when the operator
+	// creates the pods, it is left out of the schema of the CRD.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Template corev1.PodTemplateSpec `json:"template"`
+}
+
+// PaddleJobStatus is the status for a PaddleJob resource.
+type PaddleJobStatus struct {
+	// Phase is phase of PaddleJob
+	// +kubebuilder:validation:Enum="";creating;running;succeeded;failed
+	// +optional
+	Phase v1.PaddleJobPhase `json:"phase,omitempty"`
+	// Reason is the reason of job phase failed
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Trainers is the number of active trainers, it backs the scale subresource.
+	// +optional
+	Trainers int `json:"trainers,omitempty"`
+	// ReplicaStatuses is detail status of resources
+	// +optional
+	ReplicaStatuses []*v1.TrainingResourceStatus `json:"replicaStatuses,omitempty"`
+	// Failure describes the first pod of the job which failed.
+	// +optional
+	Failure *v1.FailureInfo `json:"failure,omitempty"`
+	// PserverFailures are the last pserver failures and the action taken.
+	// +optional
+	PserverFailures []v1.PserverFailure `json:"pserverFailures,omitempty"`
+	// Restarts is the number of times the job has been restarted.
+	// +optional
+	Restarts int `json:"restarts,omitempty"`
+	// PendingPods is the number of pods of the job which are not running yet.
+	// +optional
+	PendingPods int `json:"pendingPods,omitempty"`
+	// PendingReason summarizes why the pending pods are not running, e.g.
+	// they cannot be scheduled or their image cannot be pulled.
+	// +optional
+	PendingReason string `json:"pendingReason,omitempty"`
+	// Progress is the training progress reported by the trainers.
+	// +optional
+	Progress *v1.TrainingProgress `json:"progress,omitempty"`
+	// Checkpoint is the latest checkpoint reported by the trainers.
+	// +optional
+	Checkpoint *v1.CheckpointStatus `json:"checkpoint,omitempty"`
+	// Conditions are the current conditions of the job.
+	// +optional
+	Conditions []v1.PaddleJobCondition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +resource:path=trainingjobs
+
+// PaddleJobList is a list of PaddleJob resources
+type PaddleJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	// Items means the list of paddle job/PaddleJob
+	Items []PaddleJob `json:"items"`
+}
@@ -0,0 +1,278 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file was autogenerated by deepcopy-gen. Do not edit it manually!
+
+package v1beta2
+
+import (
+	paddlepaddle_v1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	conversion "k8s.io/apimachinery/pkg/conversion"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	reflect "reflect"
+)
+
+// GetGeneratedDeepCopyFuncs returns the generated funcs, since we aren't registering them.
+//
+// Deprecated: deepcopy registration will go away when static deepcopy is fully implemented.
+func GetGeneratedDeepCopyFuncs() []conversion.GeneratedDeepCopyFunc {
+	return []conversion.GeneratedDeepCopyFunc{
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PaddleJob).DeepCopyInto(out.(*PaddleJob))
+			return nil
+		}, InType: reflect.TypeOf(&PaddleJob{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PaddleJobList).DeepCopyInto(out.(*PaddleJobList))
+			return nil
+		}, InType: reflect.TypeOf(&PaddleJobList{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PaddleJobSpec).DeepCopyInto(out.(*PaddleJobSpec))
+			return nil
+		}, InType: reflect.TypeOf(&PaddleJobSpec{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PaddleJobStatus).DeepCopyInto(out.(*PaddleJobStatus))
+			return nil
+		}, InType: reflect.TypeOf(&PaddleJobStatus{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ReplicaSpec).DeepCopyInto(out.(*ReplicaSpec))
+			return nil
+		}, InType: reflect.TypeOf(&ReplicaSpec{})},
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PaddleJob) DeepCopyInto(out *PaddleJob) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PaddleJob.
+func (in *PaddleJob) DeepCopy() *PaddleJob {
+	if in == nil {
+		return nil
+	}
+	out := new(PaddleJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PaddleJob) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PaddleJobList) DeepCopyInto(out *PaddleJobList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PaddleJob, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PaddleJobList.
+func (in *PaddleJobList) DeepCopy() *PaddleJobList {
+	if in == nil {
+		return nil
+	}
+	out := new(PaddleJobList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PaddleJobList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PaddleJobSpec) DeepCopyInto(out *PaddleJobSpec) {
+	*out = *in
+	if in.ReplicaSpecs != nil {
+		in, out := &in.ReplicaSpecs, &out.ReplicaSpecs
+		*out = make(map[ReplicaType]*ReplicaSpec, len(*in))
+		for key, val := range *in {
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				(*out)[key] = new(ReplicaSpec)
+				val.DeepCopyInto((*out)[key])
+			}
+		}
+	}
+	if in.StallTimeout != nil {
+		in, out := &in.StallTimeout, &out.StallTimeout
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	if in.Checkpoint != nil {
+		in, out := &in.Checkpoint, &out.Checkpoint
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(paddlepaddle_v1.CheckpointSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Master != nil {
+		in, out := &in.Master, &out.Master
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(paddlepaddle_v1.MasterSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PaddleJobSpec.
+func (in *PaddleJobSpec) DeepCopy() *PaddleJobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PaddleJobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PaddleJobStatus) DeepCopyInto(out *PaddleJobStatus) {
+	*out = *in
+	if in.ReplicaStatuses != nil {
+		in, out := &in.ReplicaStatuses, &out.ReplicaStatuses
+		*out = make([]*paddlepaddle_v1.TrainingResourceStatus, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(paddlepaddle_v1.TrainingResourceStatus)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
+	if in.Failure != nil {
+		in, out := &in.Failure, &out.Failure
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(paddlepaddle_v1.FailureInfo)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.PserverFailures != nil {
+		in, out := &in.PserverFailures, &out.PserverFailures
+		*out = make([]paddlepaddle_v1.PserverFailure, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(paddlepaddle_v1.TrainingProgress)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Checkpoint != nil {
+		in, out := &in.Checkpoint, &out.Checkpoint
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(paddlepaddle_v1.CheckpointStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]paddlepaddle_v1.PaddleJobCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PaddleJobStatus.
+func (in *PaddleJobStatus) DeepCopy() *PaddleJobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PaddleJobStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaSpec) DeepCopyInto(out *ReplicaSpec) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	in.Template.DeepCopyInto(&out.Template)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaSpec.
+func (in *ReplicaSpec) DeepCopy() *ReplicaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -18,10 +18,13 @@ import (
 	"fmt"
 
 	paddleresource "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+	"github.com/paddlepaddle/paddlejob/pkg/updater"
 	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 )
 
 // Cluster is our interface to the Kubernetes cluster. It can inquiry
@@ -34,12 +37,14 @@ import (
 // this source file.
 type Cluster struct {
 	clientset *kubernetes.Clientset
+	podLister corelisters.PodLister
 }
 
 // newCluster create a new instance of K8sCluster.
-func newCluster(clientset *kubernetes.Clientset) *Cluster {
+func newCluster(clientset *kubernetes.Clientset, podLister corelisters.PodLister) *Cluster {
 	return &Cluster{
 		clientset: clientset,
+		podLister: podLister,
 	}
 }
 
@@ -60,11 +65,13 @@ func (c Cluster) JobPods(job *paddleresource.PaddleJob) (total, running, succeed
 	if err != nil {
 		return
 	}
-	// get pods of the job
-	jobPods, err := c.clientset.CoreV1().
-		Pods(job.ObjectMeta.Namespace).
-		List(metav1.ListOptions{LabelSelector: "paddle-job=" + job.ObjectMeta.Name})
-	for _, pod := range jobPods.Items {
+	// get pods of the job from the informer cache
+	jobPods, err := c.podLister.Pods(job.ObjectMeta.Namespace).
+		List(labels.SelectorFromSet(labels.Set{
+			updater.JobNameLabel: job.ObjectMeta.Name,
+			updater.RoleLabel:    "trainer",
+		}))
+	for _, pod := range jobPods {
 		total++
 		// pod.ObjectMeta.DeletionTimestamp means pod is terminating
 		if pod.ObjectMeta.DeletionTimestamp == nil && pod.Status.Phase == v1.PodRunning {
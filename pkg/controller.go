@@ -25,19 +25,21 @@
 package paddlejob
 
 import (
-	"encoding/json"
 	"sync"
 
 	log "github.com/inconshreveable/log15"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/kubernetes/pkg/api"
 
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	paddleJobClient "github.com/paddlepaddle/paddlejob/pkg/client/clientset/versioned"
 	paddleresource "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+	"github.com/paddlepaddle/paddlejob/pkg/updater"
 )
 
 // Controller for dispatching PaddleJob resource.
@@ -45,22 +47,65 @@ type Controller struct {
 	client     *rest.RESTClient
 	clientset  *kubernetes.Clientset
 	paddleJobSynced *PaddleJobSynced
+
+	// informerFactory shares the pod informer between the cluster and
+	// the updaters.
+	informerFactory informers.SharedInformerFactory
+	podsSynced      cache.InformerSynced
+
+	// sweep configures the sweeper of the orphaned children of the
+	// PaddleJobs.
+	sweep updater.SweeperConfig
 }
 
 // New construct a new Controller struct
-func New(c *rest.RESTClient, cs *kubernetes.Clientset) (*Controller, error) {
-	cluster := newCluster(cs)
-	as := newPaddleJobSynced(cluster)
+func New(c *rest.RESTClient, cs *kubernetes.Clientset, config *updater.Config, sweep updater.SweeperConfig) (*Controller, error) {
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	pods := informerFactory.Core().V1().Pods()
+	cluster := newCluster(cs, pods.Lister())
+	as := newPaddleJobSynced(cluster, withConfig(config), withPodLister(pods.Lister()))
+	// The updaters react to the changes of the pods of their job instead
+	// of polling them.
+	pods.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			as.OnPod(obj.(*corev1.Pod))
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			as.OnPod(newObj.(*corev1.Pod))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if pod, ok := obj.(*corev1.Pod); ok {
+				as.OnPod(pod)
+			}
+		},
+	})
 
 	return &Controller{
 		client:     c,
 		clientset:  cs,
 		paddleJobSynced: as,
+		informerFactory: informerFactory,
+		podsSynced:      pods.Informer().HasSynced,
+		sweep:           sweep,
 	}, nil
 }
 
 // Run start to watch kubernetes events and do handlers.
 func (c *Controller) Run(paddleJobClient paddleJobClient.Interface) {
+	stopCh := make(chan struct{}) // A channel will never close.
+	c.informerFactory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, c.podsSynced) {
+		log.Error("failed to sync the pod informer cache")
+		return
+	}
+
+	if c.sweep.Interval > 0 {
+		go updater.NewSweeper(c.clientset, paddleJobClient, c.sweep).Run(stopCh)
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(2)
 	go func() {
@@ -109,32 +154,9 @@ func (c *Controller) WatchPaddleJobs() {
 func (c *Controller) onAdd(obj interface{}) {
 	job := obj.(*paddleresource.PaddleJob)
 	log.Debug("PaddleJob resource added", "name", job.ObjectMeta.Name)
+	// The updater started by paddleJobSynced fills in the
+	// defaults, parses the job and creates all its resources.
 	c.paddleJobSynced.OnAdd(job)
-
-	// TODO(gongwb):open it when all are ready.
-	// All-are-ready means:
-	//  create trainjob from paddlectl
-	//  scheduler can schedule trainjobs
-	var parser DefaultJobParser
-	p := parser.ParseToPserver(job)
-	t := parser.ParseToTrainer(job)
-
-	b, _ := json.MarshalIndent(p, "", "   ")
-	log.Debug("create pserver:" + string(b))
-
-	b, _ = json.MarshalIndent(t, "", "   ")
-	log.Debug("create trainer-job:" + string(b))
-
-	// create all resources
-	_, err := c.clientset.ExtensionsV1beta1().ReplicaSets(p.ObjectMeta.Namespace).Create(p)
-	if err != nil {
-		log.Error("create pserver", "error", err)
-	}
-
-	_, err = c.clientset.BatchV1().Jobs(t.ObjectMeta.Namespace).Create(t)
-	if err != nil {
-		log.Error("create trainer", "error", err)
-	}
 }
 
 func (c *Controller) onUpdate(oldObj, newObj interface{}) {
@@ -0,0 +1,262 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package crd installs and upgrades the PaddleJob
+// CustomResourceDefinition from the definition embedded in the
+// operator binary, which is generated from manifests/crd.yaml.
+package crd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ghodss/yaml"
+	log "github.com/golang/glog"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientgoapi "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	crdResource  = "customresourcedefinitions"
+	pollInterval = time.Second
+)
+
+// object is a generic JSON object of the Kubernetes API.
+type object map[string]interface{}
+
+// Install creates the PaddleJob CRD, or updates it to the embedded
+// definition if it exists, and waits at most timeout until the CRD is
+// Established. It refuses to touch a CRD storing a version the embedded
+// definition does not serve. The versions of the CRD are converted by
+// webhook if the API server can call it.
+func Install(config *rest.Config, timeout time.Duration, webhook Webhook) error {
+	client, err := newRESTClient(config)
+	if err != nil {
+		return err
+	}
+
+	desired, err := definition()
+	if err != nil {
+		return err
+	}
+	name := desired.name()
+
+	raw, err := client.Get().Resource(crdResource).Name(name).Do().Raw()
+	if errors.IsNotFound(err) {
+		configureWebhook(desired, nil, webhook)
+		log.Infof("Creating CRD %v", name)
+		body, _ := json.Marshal(desired)
+		if err := client.Post().Resource(crdResource).Body(body).Do().Error(); err != nil {
+			return fmt.Errorf("create CRD %v error: %v", name, err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("get CRD %v error: %v", name, err)
+	} else {
+		existing := object{}
+		if err := json.Unmarshal(raw, &existing); err != nil {
+			return err
+		}
+		if err := checkCompatible(existing, desired); err != nil {
+			return err
+		}
+		configureWebhook(desired, existing, webhook)
+		log.Infof("Updating CRD %v", name)
+		metadata := desired.get("metadata")
+		metadata["resourceVersion"] = existing.get("metadata")["resourceVersion"]
+		body, _ := json.Marshal(desired)
+		if err := client.Put().Resource(crdResource).Name(name).Body(body).Do().Error(); err != nil {
+			return fmt.Errorf("update CRD %v error: %v", name, err)
+		}
+	}
+
+	return waitEstablished(client, name, timeout)
+}
+
+// Webhook is the conversion webhook the API server calls.
+type Webhook struct {
+	// Serve is whether the operator serves the conversion webhook.
+	Serve bool
+	// ServiceName and ServiceNamespace are the service of the webhook.
+	ServiceName      string
+	ServiceNamespace string
+	// CABundle is the CA the API server trusts when calling the webhook.
+	// The CA bundle of the existing CRD is kept if empty.
+	CABundle []byte
+}
+
+// conversionPath is the path the operator serves the conversion webhook on.
+const conversionPath = "/convert"
+
+// configureWebhook sets the conversion of desired. The versions are
+// converted by webhook only if the operator serves it and the API server has
+// a CA bundle to trust it with, otherwise every request to the versions which
+// are not stored would fail, so they are not converted. Without conversion
+// the API server only rewrites the apiVersion, so the versions which are not
+// stored are not served either.
+// existing is the installed CRD, or nil if there is none.
+func configureWebhook(desired, existing object, webhook Webhook) {
+	var caBundle string
+	if len(webhook.CABundle) > 0 {
+		caBundle = base64.StdEncoding.EncodeToString(webhook.CABundle)
+	} else if existing != nil {
+		// The bundle is usually injected after the installation, an
+		// upgrade without one must not wipe it.
+		existingConfig := existing.get("spec").get("conversion").get("webhook").get("clientConfig")
+		caBundle, _ = existingConfig["caBundle"].(string)
+	}
+
+	spec := desired.get("spec")
+	if !webhook.Serve || caBundle == "" || webhook.ServiceName == "" || webhook.ServiceNamespace == "" {
+		if webhook.Serve {
+			log.Warningf("The conversion webhook of CRD %v has no CA bundle or service, its versions are not converted", desired.name())
+		}
+		spec["conversion"] = map[string]interface{}{"strategy": "None"}
+		serveConverted(desired, false)
+		return
+	}
+	serveConverted(desired, true)
+	spec["conversion"] = map[string]interface{}{
+		"strategy": "Webhook",
+		"webhook": map[string]interface{}{
+			"clientConfig": map[string]interface{}{
+				"service": map[string]interface{}{
+					"name":      webhook.ServiceName,
+					"namespace": webhook.ServiceNamespace,
+					"path":      conversionPath,
+				},
+				"caBundle": caBundle,
+			},
+			"conversionReviewVersions": []interface{}{"v1"},
+		},
+	}
+}
+
+// serveConverted sets whether the versions of the CRD o which are not stored,
+// and thus need a conversion, are served.
+func serveConverted(o object, served bool) {
+	versions, _ := o.get("spec")["versions"].([]interface{})
+	for _, v := range versions {
+		version := object(v.(map[string]interface{}))
+		if storage, _ := version["storage"].(bool); !storage {
+			version["served"] = served
+		}
+	}
+}
+
+// newRESTClient returns a client of the apiextensions.k8s.io/v1 API.
+func newRESTClient(config *rest.Config) (*rest.RESTClient, error) {
+	c := *config
+	c.GroupVersion = &schema.GroupVersion{Group: "apiextensions.k8s.io", Version: "v1"}
+	c.APIPath = "/apis"
+	c.ContentType = runtime.ContentTypeJSON
+	c.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: clientgoapi.Codecs}
+	return rest.RESTClientFor(&c)
+}
+
+// definition decodes the embedded CRD.
+func definition() (object, error) {
+	j, err := yaml.YAMLToJSON([]byte(crdYAML))
+	if err != nil {
+		return nil, fmt.Errorf("decode embedded CRD error: %v", err)
+	}
+	o := object{}
+	if err := json.Unmarshal(j, &o); err != nil {
+		return nil, fmt.Errorf("decode embedded CRD error: %v", err)
+	}
+	return o, nil
+}
+
+// checkCompatible returns an error if existing stores a version which is
+// not served by desired, the objects of that version could not be read
+// after the upgrade.
+func checkCompatible(existing, desired object) error {
+	served := map[string]bool{}
+	versions, _ := desired.get("spec")["versions"].([]interface{})
+	for _, v := range versions {
+		version := object(v.(map[string]interface{}))
+		if isServed, _ := version["served"].(bool); !isServed {
+			continue
+		}
+		name, _ := version["name"].(string)
+		served[name] = true
+	}
+
+	stored, _ := existing.get("status")["storedVersions"].([]interface{})
+	for _, v := range stored {
+		if !served[v.(string)] {
+			return fmt.Errorf("CRD %v has stored version %v which is not served by this operator, "+
+				"migrate the stored objects before upgrading", desired.name(), v)
+		}
+	}
+	return nil
+}
+
+// waitEstablished waits until the CRD name is Established.
+func waitEstablished(client *rest.RESTClient, name string, timeout time.Duration) error {
+	err := wait.PollImmediate(pollInterval, timeout, func() (bool, error) {
+		raw, err := client.Get().Resource(crdResource).Name(name).Do().Raw()
+		if err != nil {
+			return false, nil
+		}
+		crd := object{}
+		if err := json.Unmarshal(raw, &crd); err != nil {
+			return false, err
+		}
+		conditions, _ := crd.get("status")["conditions"].([]interface{})
+		for _, c := range conditions {
+			cond := object(c.(map[string]interface{}))
+			switch cond["type"] {
+			case "Established":
+				if cond["status"] == "True" {
+					return true, nil
+				}
+			case "NamesAccepted":
+				if cond["status"] == "False" {
+					return false, fmt.Errorf("names of CRD %v are not accepted: %v", name, cond["message"])
+				}
+			}
+		}
+		return false, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("CRD %v is not established after %v", name, timeout)
+	}
+	if err == nil {
+		log.Infof("CRD %v is established", name)
+	}
+	return err
+}
+
+// get returns the object under key, or an empty one if it is missing.
+func (o object) get(key string) object {
+	v, ok := o[key].(map[string]interface{})
+	if !ok {
+		v = map[string]interface{}{}
+		o[key] = v
+	}
+	return object(v)
+}
+
+func (o object) name() string {
+	name, _ := o.get("metadata")["name"].(string)
+	return name
+}
@@ -0,0 +1,209 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+	padv1beta2 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1beta2"
+)
+
+func TestDefinition(t *testing.T) {
+	d, err := definition()
+	assert.Nil(t, err)
+	assert.Equal(t, "paddlejobs.paddlepaddle.org", d.name())
+}
+
+func TestDefinitionSize(t *testing.T) {
+	d, err := definition()
+	assert.Nil(t, err)
+	raw, err := json.Marshal(d)
+	assert.Nil(t, err)
+	// kubectl apply keeps the whole CRD in an annotation of at most 256KiB.
+	assert.True(t, len(raw) < 256*1024, "the CRD has %d bytes", len(raw))
+}
+
+func TestCheckCompatible(t *testing.T) {
+	desired, err := definition()
+	assert.Nil(t, err)
+
+	existing := object{"status": map[string]interface{}{
+		"storedVersions": []interface{}{"v1"},
+	}}
+	assert.Nil(t, checkCompatible(existing, desired))
+
+	existing = object{"status": map[string]interface{}{
+		"storedVersions": []interface{}{"v1alpha1", "v1"},
+	}}
+	assert.NotNil(t, checkCompatible(existing, desired))
+}
+
+func TestConfigureWebhook(t *testing.T) {
+	clientConfig := func(o object) object {
+		return o.get("spec").get("conversion").get("webhook").get("clientConfig")
+	}
+	strategy := func(o object) interface{} {
+		return o.get("spec").get("conversion")["strategy"]
+	}
+	served := func(o object, name string) interface{} {
+		versions, _ := o.get("spec")["versions"].([]interface{})
+		for _, v := range versions {
+			if version := object(v.(map[string]interface{})); version["name"] == name {
+				return version["served"]
+			}
+		}
+		return nil
+	}
+	webhook := Webhook{Serve: true, ServiceName: "webhook", ServiceNamespace: "paddle", CABundle: []byte("ca")}
+
+	desired, err := definition()
+	assert.Nil(t, err)
+	assert.Equal(t, "None", strategy(desired))
+	assert.Equal(t, false, served(desired, "v1beta2"))
+	configureWebhook(desired, nil, webhook)
+	assert.Equal(t, "Webhook", strategy(desired))
+	assert.Equal(t, true, served(desired, "v1"))
+	assert.Equal(t, true, served(desired, "v1beta2"))
+	assert.Equal(t, "webhook", clientConfig(desired).get("service")["name"])
+	assert.Equal(t, "paddle", clientConfig(desired).get("service")["namespace"])
+	assert.Equal(t, "/convert", clientConfig(desired).get("service")["path"])
+	assert.Equal(t, "Y2E=", clientConfig(desired)["caBundle"])
+
+	// The installed CA bundle is kept.
+	existing := object{}
+	clientConfig(existing)["caBundle"] = "aW5qZWN0ZWQ="
+	webhook.CABundle = nil
+	desired, err = definition()
+	assert.Nil(t, err)
+	configureWebhook(desired, existing, webhook)
+	assert.Equal(t, "Webhook", strategy(desired))
+	assert.Equal(t, "aW5qZWN0ZWQ=", clientConfig(desired)["caBundle"])
+
+	// A new CA bundle replaces the installed one.
+	webhook.CABundle = []byte("ca")
+	desired, err = definition()
+	assert.Nil(t, err)
+	configureWebhook(desired, existing, webhook)
+	assert.Equal(t, "Y2E=", clientConfig(desired)["caBundle"])
+
+	// The versions are not converted without a CA bundle or if the
+	// operator does not serve the webhook.
+	desired, err = definition()
+	assert.Nil(t, err)
+	configureWebhook(desired, nil, Webhook{Serve: true, ServiceName: "webhook", ServiceNamespace: "paddle"})
+	assert.Equal(t, "None", strategy(desired))
+	assert.Nil(t, desired.get("spec").get("conversion")["webhook"])
+	assert.Equal(t, true, served(desired, "v1"))
+	assert.Equal(t, false, served(desired, "v1beta2"))
+	desired, err = definition()
+	assert.Nil(t, err)
+	configureWebhook(desired, existing, Webhook{ServiceName: "webhook", ServiceNamespace: "paddle"})
+	assert.Equal(t, "None", strategy(desired))
+	assert.Equal(t, false, served(desired, "v1beta2"))
+}
+
+// versionSchema returns the schema of version in the CRD d.
+func versionSchema(d object, version string) object {
+	versions, _ := d.get("spec")["versions"].([]interface{})
+	for _, v := range versions {
+		v := object(v.(map[string]interface{}))
+		if v["name"] == version {
+			return v.get("schema").get("openAPIV3Schema")
+		}
+	}
+	return nil
+}
+
+// prune drops the fields of v which are not in the structural schema s, as
+// the API server does before it persists a custom resource.
+func prune(v interface{}, s object) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		preserve, _ := s["x-kubernetes-preserve-unknown-fields"].(bool)
+		properties, hasProperties := s["properties"].(map[string]interface{})
+		additional, hasAdditional := s["additionalProperties"].(map[string]interface{})
+		for key, value := range v {
+			switch {
+			case hasProperties && properties[key] != nil:
+				prune(value, object(properties[key].(map[string]interface{})))
+			case hasAdditional:
+				prune(value, object(additional))
+			case !preserve:
+				delete(v, key)
+			}
+		}
+	case []interface{}:
+		if items, ok := s["items"].(map[string]interface{}); ok {
+			for _, item := range v {
+				prune(item, object(items))
+			}
+		}
+	}
+}
+
+// roundTrip returns job as stored by the API server with the schema of
+// version of the embedded CRD.
+func roundTrip(t *testing.T, version string, job interface{}) (before, after map[string]interface{}) {
+	d, err := definition()
+	assert.Nil(t, err)
+	s := versionSchema(d, version)
+	assert.NotNil(t, s)
+
+	raw, err := json.Marshal(job)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(raw, &before))
+	assert.Nil(t, json.Unmarshal(raw, &after))
+	// The metadata of the resource itself is not pruned.
+	metadata := after["metadata"]
+	delete(after, "metadata")
+	prune(after, s)
+	after["metadata"] = metadata
+	return before, after
+}
+
+func TestSchemaKeepsEmbeddedMetadata(t *testing.T) {
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:        name,
+			Labels:      map[string]string{"paddle-job": "mnist"},
+			Annotations: map[string]string{"description": "mnist"},
+		}
+	}
+	template := corev1.PodTemplateSpec{ObjectMeta: meta("")}
+	job := &padv1.PaddleJob{ObjectMeta: metav1.ObjectMeta{Name: "mnist"}}
+	job.Spec.Pserver.Template = template.DeepCopy()
+	job.Spec.Pserver.ReplicaSpec = &v1beta1.ReplicaSet{ObjectMeta: meta("mnist-pserver")}
+	job.Spec.Pserver.ReplicaSpec.Spec.Template = template
+	job.Spec.Trainer.ReplicaSpec = &batchv1.Job{ObjectMeta: meta("mnist-trainer")}
+	job.Spec.Trainer.ReplicaSpec.Spec.Template = template
+	before, after := roundTrip(t, "v1", job)
+	assert.Equal(t, before, after)
+
+	replicas := int32(1)
+	beta := &padv1beta2.PaddleJob{ObjectMeta: metav1.ObjectMeta{Name: "mnist"}}
+	beta.Spec.ReplicaSpecs = map[padv1beta2.ReplicaType]*padv1beta2.ReplicaSpec{
+		padv1beta2.ReplicaTypeTrainer: {Replicas: &replicas, Template: template},
+	}
+	before, after = roundTrip(t, "v1beta2", beta)
+	assert.Equal(t, before, after)
+}
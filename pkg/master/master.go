@@ -0,0 +1,326 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package master is the task dispatcher of fault tolerant PaddleJobs. It
+// splits the chunks of a dataset into tasks and leases them to the trainers
+// pass after pass. The task of a trainer which does not finish it in time,
+// e.g. because it died, is given to another trainer.
+package master
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+)
+
+var (
+	// ErrNoTask is returned when all the tasks of the current pass are
+	// leased, the trainer should ask again later.
+	ErrNoTask = errors.New("no task available")
+	// ErrDone is returned when all the passes are done.
+	ErrDone = errors.New("all passes are done")
+	// ErrUnknownTask is returned when a trainer reports a task it does not
+	// hold any more, e.g. because its lease expired.
+	ErrUnknownTask = errors.New("unknown task")
+)
+
+// Config is the configuration of the master.
+type Config struct {
+	// Passes is the number of passes over the dataset.
+	Passes int
+	// ChunksPerTask is the number of chunks of a task.
+	ChunksPerTask int
+	// Timeout is how long a trainer may hold a task.
+	Timeout time.Duration
+	// MaxFailures is the number of times a task may fail in a pass before
+	// it is dropped.
+	MaxFailures int
+	// StatePath is the file the queue is persisted in, the queue is not
+	// persisted if it is empty.
+	StatePath string
+}
+
+// Task is a part of the dataset trained by one trainer at a time.
+type Task struct {
+	ID       int      `json:"id"`
+	Pass     int      `json:"pass"`
+	Chunks   []string `json:"chunks"`
+	Failures int      `json:"failures"`
+}
+
+// lease is a task held by a trainer.
+type lease struct {
+	Task     Task      `json:"task"`
+	Trainer  string    `json:"trainer"`
+	Deadline time.Time `json:"deadline"`
+}
+
+// queue is the persisted state of the master.
+type queue struct {
+	Pass    int            `json:"pass"`
+	Todo    []Task         `json:"todo"`
+	Pending map[int]*lease `json:"pending"`
+	Done    []Task         `json:"done"`
+	Dropped []Task         `json:"dropped"`
+}
+
+// Status is the progress of the master.
+type Status struct {
+	Pass     int  `json:"pass"`
+	Passes   int  `json:"passes"`
+	Todo     int  `json:"todo"`
+	Pending  int  `json:"pending"`
+	Done     int  `json:"done"`
+	Dropped  int  `json:"dropped"`
+	Finished bool `json:"finished"`
+}
+
+// Service leases the tasks of a dataset to the trainers.
+type Service struct {
+	mu     sync.Mutex
+	config Config
+	queue  queue
+	now    func() time.Time
+}
+
+// New creates a master dispatching chunks. The queue is restored from the
+// state file if it exists.
+func New(chunks []string, config Config) (*Service, error) {
+	if config.ChunksPerTask <= 0 {
+		config.ChunksPerTask = 1
+	}
+	s := &Service{config: config, now: time.Now}
+
+	if config.StatePath != "" {
+		data, err := ioutil.ReadFile(config.StatePath)
+		if err == nil {
+			if err := json.Unmarshal(data, &s.queue); err != nil {
+				return nil, fmt.Errorf("restore queue from %v: %v", config.StatePath, err)
+			}
+			if s.queue.Pending == nil {
+				s.queue.Pending = make(map[int]*lease)
+			}
+			log.Infof("restored queue of pass %d from %v", s.queue.Pass, config.StatePath)
+			return s, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("the dataset has no chunks")
+	}
+	s.queue = queue{Todo: partition(chunks, config.ChunksPerTask), Pending: make(map[int]*lease)}
+	return s, s.save()
+}
+
+// ReadManifest reads the chunks of a dataset from a manifest listing one
+// chunk per line. Empty lines and lines starting with # are skipped.
+func ReadManifest(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var chunks []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			chunks = append(chunks, line)
+		}
+	}
+	return chunks, scanner.Err()
+}
+
+// partition splits chunks into tasks of n chunks.
+func partition(chunks []string, n int) []Task {
+	var tasks []Task
+	for i := 0; i < len(chunks); i += n {
+		end := i + n
+		if end > len(chunks) {
+			end = len(chunks)
+		}
+		tasks = append(tasks, Task{ID: len(tasks), Chunks: chunks[i:end]})
+	}
+	return tasks
+}
+
+// GetTask leases a task of the current pass to trainer.
+func (s *Service) GetTask(trainer string) (Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire()
+	if s.finished() {
+		return Task{}, ErrDone
+	}
+	if len(s.queue.Todo) == 0 {
+		return Task{}, ErrNoTask
+	}
+	t := s.queue.Todo[0]
+	s.queue.Todo = s.queue.Todo[1:]
+	s.queue.Pending[t.ID] = &lease{Task: t, Trainer: trainer, Deadline: s.now().Add(s.config.Timeout)}
+	return t, s.save()
+}
+
+// TaskFinished records that the task id of pass is done.
+func (s *Service) TaskFinished(id, pass int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.release(id, pass)
+	if err != nil {
+		return err
+	}
+	s.queue.Done = append(s.queue.Done, l.Task)
+	s.nextPass()
+	return s.save()
+}
+
+// TaskFailed records that the task id of pass failed, it is given to
+// another trainer unless it failed too many times.
+func (s *Service) TaskFailed(id, pass int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.release(id, pass)
+	if err != nil {
+		return err
+	}
+	s.fail(l)
+	s.nextPass()
+	return s.save()
+}
+
+// Status returns the progress of the master.
+func (s *Service) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire()
+	return Status{
+		Pass:     s.queue.Pass,
+		Passes:   s.config.Passes,
+		Todo:     len(s.queue.Todo),
+		Pending:  len(s.queue.Pending),
+		Done:     len(s.queue.Done),
+		Dropped:  len(s.queue.Dropped),
+		Finished: s.finished(),
+	}
+}
+
+func (s *Service) finished() bool {
+	return s.queue.Pass >= s.config.Passes
+}
+
+// release removes the lease of the task id of pass.
+func (s *Service) release(id, pass int) (*lease, error) {
+	l, ok := s.queue.Pending[id]
+	if !ok || l.Task.Pass != pass {
+		return nil, ErrUnknownTask
+	}
+	delete(s.queue.Pending, id)
+	return l, nil
+}
+
+// fail puts the task of a failed lease back in the queue, or drops it if
+// it failed too many times.
+func (s *Service) fail(l *lease) {
+	t := l.Task
+	t.Failures++
+	if s.config.MaxFailures > 0 && t.Failures > s.config.MaxFailures {
+		log.Warningf("drop task %d of pass %d failed %d times", t.ID, t.Pass, t.Failures)
+		s.queue.Dropped = append(s.queue.Dropped, t)
+		return
+	}
+	s.queue.Todo = append(s.queue.Todo, t)
+}
+
+// expire takes back the tasks whose lease expired.
+func (s *Service) expire() {
+	now := s.now()
+	expired := false
+	for id, l := range s.queue.Pending {
+		if now.After(l.Deadline) {
+			log.Warningf("lease of task %d by trainer %v expired", id, l.Trainer)
+			delete(s.queue.Pending, id)
+			s.fail(l)
+			expired = true
+		}
+	}
+	if expired {
+		s.nextPass()
+		if err := s.save(); err != nil {
+			log.Errorf("save queue error: %v", err)
+		}
+	}
+}
+
+// nextPass starts the next pass once all the tasks of the current one are
+// done or dropped. The dropped tasks are tried again in the next pass.
+func (s *Service) nextPass() {
+	if len(s.queue.Todo) > 0 || len(s.queue.Pending) > 0 || s.finished() {
+		return
+	}
+	s.queue.Pass++
+	log.Infof("pass %d of %d done", s.queue.Pass, s.config.Passes)
+	if s.finished() {
+		return
+	}
+	tasks := append(s.queue.Done, s.queue.Dropped...)
+	s.queue.Todo = make([]Task, 0, len(tasks))
+	for _, t := range tasks {
+		t.Pass, t.Failures = s.queue.Pass, 0
+		s.queue.Todo = append(s.queue.Todo, t)
+	}
+	s.queue.Done, s.queue.Dropped = nil, nil
+}
+
+// save persists the queue in the state file, through a temporary file so
+// the state file is never partially written.
+func (s *Service) save() error {
+	if s.config.StatePath == "" {
+		return nil
+	}
+	data, err := json.Marshal(&s.queue)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.config.StatePath), ".master-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.config.StatePath)
+}
@@ -0,0 +1,104 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package master
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasses(t *testing.T) {
+	s, err := New([]string{"a", "b", "c"}, Config{Passes: 2, ChunksPerTask: 2, Timeout: time.Minute})
+	assert.NoError(t, err)
+
+	t1, err := s.GetTask("trainer-0")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, t1.Chunks)
+	t2, err := s.GetTask("trainer-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c"}, t2.Chunks)
+	_, err = s.GetTask("trainer-2")
+	assert.Equal(t, ErrNoTask, err)
+
+	assert.NoError(t, s.TaskFinished(t1.ID, 0))
+	assert.Equal(t, ErrUnknownTask, s.TaskFinished(t1.ID, 0))
+	assert.NoError(t, s.TaskFinished(t2.ID, 0))
+	assert.Equal(t, Status{Pass: 1, Passes: 2, Todo: 2}, s.Status())
+
+	for i := 0; i < 2; i++ {
+		task, err := s.GetTask("trainer-0")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, task.Pass)
+		assert.NoError(t, s.TaskFinished(task.ID, task.Pass))
+	}
+	_, err = s.GetTask("trainer-0")
+	assert.Equal(t, ErrDone, err)
+	assert.True(t, s.Status().Finished)
+}
+
+func TestExpireAndFail(t *testing.T) {
+	now := time.Unix(0, 0)
+	s, err := New([]string{"a"}, Config{Passes: 1, Timeout: time.Minute, MaxFailures: 1})
+	assert.NoError(t, err)
+	s.now = func() time.Time { return now }
+
+	task, err := s.GetTask("dead")
+	assert.NoError(t, err)
+
+	// The task of a dead trainer is given to another one.
+	now = now.Add(2 * time.Minute)
+	again, err := s.GetTask("alive")
+	assert.NoError(t, err)
+	assert.Equal(t, task.ID, again.ID)
+	assert.Equal(t, 1, again.Failures)
+	assert.Equal(t, ErrUnknownTask, s.TaskFinished(task.ID, 1))
+
+	// The second failure drops it.
+	assert.NoError(t, s.TaskFailed(again.ID, again.Pass))
+	assert.True(t, s.Status().Finished)
+}
+
+func TestPersistQueue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "master")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	manifest := filepath.Join(dir, "manifest")
+	assert.NoError(t, ioutil.WriteFile(manifest, []byte("# chunks\na\n\nb\n"), 0644))
+	chunks, err := ReadManifest(manifest)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, chunks)
+
+	config := Config{Passes: 1, Timeout: time.Minute, StatePath: filepath.Join(dir, "state.json")}
+	s, err := New(chunks, config)
+	assert.NoError(t, err)
+	task, err := s.GetTask("trainer-0")
+	assert.NoError(t, err)
+	assert.NoError(t, s.TaskFinished(task.ID, task.Pass))
+	leased, err := s.GetTask("trainer-0")
+	assert.NoError(t, err)
+
+	// A restarted master resumes the queue, the leased task included.
+	restored, err := New(nil, config)
+	assert.NoError(t, err)
+	assert.Equal(t, Status{Passes: 1, Pending: 1, Done: 1}, restored.Status())
+	assert.NoError(t, restored.TaskFinished(leased.ID, leased.Pass))
+	assert.True(t, restored.Status().Finished)
+}
@@ -0,0 +1,112 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/golang/glog"
+)
+
+const (
+	// TaskPath leases a task to the trainer given in the trainer query
+	// parameter. It answers 204 if no task is available yet and 410 once
+	// all the passes are done.
+	TaskPath = "/task"
+	// FinishPath reports a task done, the body is {"id": 1, "pass": 0}.
+	FinishPath = "/task/finish"
+	// FailPath reports a task failed, the body is {"id": 1, "pass": 0}.
+	FailPath = "/task/fail"
+	// StatusPath returns the progress of the master.
+	StatusPath = "/status"
+)
+
+// taskRef identifies a task in the requests of the trainers.
+type taskRef struct {
+	ID   int `json:"id"`
+	Pass int `json:"pass"`
+}
+
+// Handler returns the HTTP handler of the master.
+func (s *Service) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(TaskPath, s.serveTask)
+	mux.HandleFunc(FinishPath, s.serveReport(s.TaskFinished))
+	mux.HandleFunc(FailPath, s.serveReport(s.TaskFailed))
+	mux.HandleFunc(StatusPath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, s.Status())
+	})
+	return mux
+}
+
+// Run serves the master on addr, it blocks until the server fails.
+func (s *Service) Run(addr string) error {
+	log.Infof("serving master on %v", addr)
+	return http.ListenAndServe(addr, s.Handler())
+}
+
+func (s *Service) serveTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	t, err := s.GetTask(r.URL.Query().Get("trainer"))
+	switch err {
+	case nil:
+		writeJSON(w, t)
+	case ErrNoTask:
+		w.WriteHeader(http.StatusNoContent)
+	case ErrDone:
+		http.Error(w, err.Error(), http.StatusGone)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// serveReport serves the reports of the trainers about their tasks.
+func (s *Service) serveReport(report func(id, pass int) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		ref := taskRef{}
+		if err := json.NewDecoder(r.Body).Decode(&ref); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		switch err := report(ref.ID, ref.Pass); err {
+		case nil:
+			w.WriteHeader(http.StatusOK)
+		case ErrUnknownTask:
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	resp, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(resp); err != nil {
+		log.Errorf("write response error: %v", err)
+	}
+}
@@ -15,41 +15,36 @@
 package paddlejob
 
 import (
-	"time"
-
-	// TODO(typhoonzero): this package still depends on k8s API, try to remove this.
-	batchv1 "k8s.io/api/batch/v1"
-	"k8s.io/client-go/kubernetes"
 	log "github.com/inconshreveable/log15"
 	paddleresource "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
 	paddleJobClient "github.com/paddlepaddle/paddlejob/pkg/client/clientset/versioned"
 	"github.com/paddlepaddle/paddlejob/pkg/updater"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 )
 
-const (
-	defaultLoopDur = time.Second * 5
-)
-
-type job struct {
-	Config     *paddleresource.PaddleJob
-	TrainerJob *batchv1.Job
-}
-
 // PaddleJobSynced launches the training jobs.
 type PaddleJobSynced struct {
-	ticker         *time.Ticker
-	cluster        *Cluster
-	jobs           map[string]*job
-	eventCh        chan event
+	cluster         *Cluster
+	config          *updater.Config
+	clientset       kubernetes.Interface
+	paddleJobClient paddleJobClient.Interface
+	podLister       corelisters.PodLister
+	// updaters maps the namespace/name of a PaddleJob to the
+	// updater managing it.
+	updaters map[string]*updater.PaddleJobUpdater
+	eventCh  chan event
 }
 
 // newPaddleJobSynced creates a new PaddleJobSynced.
 func newPaddleJobSynced(cluster *Cluster, options ...func(*PaddleJobSynced)) *PaddleJobSynced {
 	c := &PaddleJobSynced{
-		cluster:        cluster,
-		ticker:         time.NewTicker(defaultLoopDur),
-		jobs:           make(map[string]*job),
-		eventCh:        make(chan event),
+		cluster:  cluster,
+		config:   &updater.Config{},
+		updaters: make(map[string]*updater.PaddleJobUpdater),
+		eventCh:  make(chan event),
 	}
 	for _, option := range options {
 		option(c)
@@ -57,7 +52,20 @@ func newPaddleJobSynced(cluster *Cluster, options ...func(*PaddleJobSynced)) *Pa
 	return c
 }
 
-type jobs []*job
+// withConfig sets the configuration passed to the updaters.
+func withConfig(config *updater.Config) func(*PaddleJobSynced) {
+	return func(c *PaddleJobSynced) {
+		c.config = config
+	}
+}
+
+// withPodLister sets the pod lister the updaters read the pods of their
+// job from.
+func withPodLister(podLister corelisters.PodLister) func(*PaddleJobSynced) {
+	return func(c *PaddleJobSynced) {
+		c.podLister = podLister
+	}
+}
 
 type eventType int
 
@@ -65,6 +73,8 @@ const (
 	add eventType = iota
 	del
 	update
+	// podChange is sent when a pod of the job changes.
+	podChange
 )
 
 type event struct {
@@ -87,91 +97,58 @@ func (a *PaddleJobSynced) OnUpdate(PaddleJob *paddleresource.PaddleJob) {
 	a.eventCh <- event{Type: update, Job: PaddleJob}
 }
 
-// updateJobList updates the data structure a.jobs according to
-// received events about the PaddleJob resource.  It returns true if
-// the controller need to do some scheduling work.  If it returns
-// false, the controller could simply go on monitoring other
-// events.
-func (a *PaddleJobSynced) updateJobList(evt event) bool {
-	log.Debug("monitor received event", "event", evt)
-	switch evt.Type {
-	case add, update:
-		j := &job{Config: evt.Job}
-		a.jobs[evt.Job.ObjectMeta.Name] = j
-		if a.tryToRetrieveTrainerJobInPaddleJob(evt.Job.ObjectMeta.Name, j) != nil {
-			return false
-		}
-	case del:
-		// TODO(helin): delete all created resources (e.g.,
-		// trainer Job, pserver Replica Set) when we schedules
-		// the resources.
-		delete(a.jobs, evt.Job.ObjectMeta.Name)
-	default:
-		log.Error("unrecognized event", "event", evt)
+// OnPod notifies the paddleJobSynced that a pod has changed, the updater of
+// the job of the pod is woken up.
+func (a *PaddleJobSynced) OnPod(pod *corev1.Pod) {
+	name := updater.JobName(pod)
+	if name == "" {
+		return
 	}
+	job := &paddleresource.PaddleJob{ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: name}}
+	a.eventCh <- event{Type: podChange, Job: job}
+}
 
-	return true
+func jobKey(job *paddleresource.PaddleJob) string {
+	return job.ObjectMeta.Namespace + "/" + job.ObjectMeta.Name
 }
 
-// findSucceededJob returns true if there is at least one training job
-// whose all pods are Succeeded.
-func (a *PaddleJobSynced) findSucceededJob(clientset *kubernetes.Clientset, paddleJobClient paddleJobClient.Interface) bool {
-	for jobName, job := range a.jobs {
-		if a.tryToRetrieveTrainerJobInPaddleJob(jobName, job) != nil {
-			continue
+// updateJobList updates the updaters according to received events
+// about the PaddleJob resource: an updater is started for every
+// added job and stopped when the job is deleted.
+func (a *PaddleJobSynced) updateJobList(evt event) {
+	log.Debug("monitor received event", "event", evt)
+	key := jobKey(evt.Job)
+	switch evt.Type {
+	case add, update:
+		if u, ok := a.updaters[key]; ok {
+			u.Modify(evt.Job)
+			return
 		}
-		total, _, succeeded, _, err := a.cluster.JobPods(job.Config)
+		u, err := updater.NewUpdater(evt.Job, a.clientset, a.paddleJobClient, a.podLister, a.config)
 		if err != nil {
-			log.Error("check if job is running failed", "error", err)
-			continue
+			log.Error("create updater failed", "name", key, "error", err)
+			return
 		}
-
-		if total == succeeded {
-			if _, err := updater.NewUpdater(job.Config, clientset, paddleJobClient); err != nil{
-				return false
-			}
-			return true
+		a.updaters[key] = u
+	case del:
+		if u, ok := a.updaters[key]; ok {
+			u.Delete()
+			delete(a.updaters, key)
 		}
-	}
-	return false
-}
-
-
-func (a *PaddleJobSynced) tryToRetrieveTrainerJobInPaddleJob(jobName string, job *job) error {
-	// TODO(helin): Because we wrote the conversion from
-	// PaddleJob into ReplicaSet and Job in paddlelcloud instead
-	// of controller (please refer to above TODO comment for
-	// details), we might suffer from the problem that when the
-	// controller calls cluster.GetTrainerJob, it doesn't
-	// return TrainerJob before the conversion is done at the
-	// paddlecloud.  After we fix this problem, we can remove the
-	// following call to cluster.GetTrainerJob and keep the one in
-	// updateJobLists.
-	if job.TrainerJob == nil {
-		tj, err := a.cluster.GetTrainerJob(job.Config)
-		if err != nil {
-			log.Error(
-				"Error getting the trainer k8s job, will sync later.",
-				"name", job.Config.ObjectMeta.Name,
-				"error", err,
-			)
-			return err
+	case podChange:
+		if u, ok := a.updaters[key]; ok {
+			u.Sync()
 		}
-		job.TrainerJob = tj
+	default:
+		log.Error("unrecognized event", "event", evt)
 	}
-	return nil
 }
 
-// Run monitors the cluster resources and training jobs in a loop.
-func (a *PaddleJobSynced) Run(clientset *kubernetes.Clientset, paddleJobClient paddleJobClient.Interface) {
-	for {
-		select {
-		case <-a.ticker.C:
-		case evt := <-a.eventCh:
-			if !a.updateJobList(evt) {
-				continue // If nothing important, go on looping.
-			}
-		}
-		a.findSucceededJob(clientset, paddleJobClient)
+// Run monitors the training jobs in a loop.
+func (a *PaddleJobSynced) Run(clientset kubernetes.Interface, paddleJobClient paddleJobClient.Interface) {
+	a.clientset = clientset
+	a.paddleJobClient = paddleJobClient
+	for evt := range a.eventCh {
+		a.updateJobList(evt)
 	}
 }
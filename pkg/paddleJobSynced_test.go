@@ -31,7 +31,7 @@ func TestRun(t *testing.T) {
 	ch := make(chan struct{})
 
 	go func() {
-		c.Run()
+		c.Run(nil, nil)
 		close(ch)
 	}()
 
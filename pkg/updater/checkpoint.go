@@ -0,0 +1,318 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/golang/glog"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// CheckpointAnnotation is the annotation of its own pod a trainer reports
+// the checkpoint it saved in, e.g. {"path": "pass-00003", "pass": 3}. The
+// path is relative to the checkpoint directory.
+const CheckpointAnnotation = "paddlepaddle.org/checkpoint"
+
+const (
+	checkpointVolumeName = "checkpoint"
+	checkpointMountPath  = "/checkpoint"
+	// expiredCheckpointsAnnotation lists the checkpoints a checkpoint
+	// collection pod deletes, in JSON.
+	expiredCheckpointsAnnotation = "paddlepaddle.org/expired-checkpoints"
+)
+
+// checkpointReport is the checkpoint reported by a trainer.
+type checkpointReport struct {
+	Path string `json:"path"`
+	Pass int    `json:"pass"`
+}
+
+// validCheckpointPath returns true if p is a path inside the checkpoint
+// directory.
+func validCheckpointPath(p string) bool {
+	return p != "" && !path.IsAbs(p) && path.Clean(p) == p && p != ".." && !strings.HasPrefix(p, "../")
+}
+
+// validateCheckpoint validates the checkpoint spec of a job.
+func validateCheckpoint(cp *padv1.CheckpointSpec) error {
+	if cp == nil {
+		return nil
+	}
+	if cp.PersistentVolumeClaim == nil && cp.HostPath == nil {
+		return fmt.Errorf("checkpoint needs a persistentVolumeClaim or a hostPath")
+	}
+	if cp.Directory != "" && !validCheckpointPath(cp.Directory) {
+		return fmt.Errorf("checkpoint directory %q is not a relative path in the volume", cp.Directory)
+	}
+	return nil
+}
+
+// checkpointVolume returns the volume and the mount of the checkpoint
+// directory of job.
+func checkpointVolume(job *padv1.PaddleJob) (corev1.Volume, corev1.VolumeMount) {
+	cp := job.Spec.Checkpoint
+	volume := corev1.Volume{Name: checkpointVolumeName}
+	if cp.PersistentVolumeClaim != nil {
+		volume.PersistentVolumeClaim = cp.PersistentVolumeClaim.DeepCopy()
+	} else {
+		volume.HostPath = cp.HostPath.DeepCopy()
+	}
+	directory := cp.Directory
+	if directory == "" {
+		directory = job.Name
+	}
+	return volume, corev1.VolumeMount{Name: checkpointVolumeName, MountPath: checkpointMountPath, SubPath: directory}
+}
+
+// setCheckpoint mounts the checkpoint directory of job in the container c
+// of template and tells it where to save and resume from checkpoints.
+func setCheckpoint(job *padv1.PaddleJob, template *corev1.PodTemplateSpec, c *corev1.Container) {
+	if job.Spec.Checkpoint == nil {
+		return
+	}
+	volume, mount := checkpointVolume(job)
+	if !hasVolume(template.Spec.Volumes, volume.Name) {
+		template.Spec.Volumes = append(template.Spec.Volumes, volume)
+	}
+	c.VolumeMounts = append(c.VolumeMounts, mount)
+	env := []corev1.EnvVar{{Name: "PADDLE_CHECKPOINT_DIR", Value: checkpointMountPath}}
+	if interval := job.Spec.Checkpoint.IntervalSeconds; interval > 0 {
+		env = append(env, corev1.EnvVar{Name: "PADDLE_CHECKPOINT_INTERVAL", Value: strconv.Itoa(interval)})
+	}
+	c.Env = padv1.MergeEnv(c.Env, env)
+	if st := job.Status.Checkpoint; st != nil && st.Latest != "" {
+		setResume(c, st.Latest)
+	}
+}
+
+// setResume tells the container c to resume from the checkpoint latest.
+func setResume(c *corev1.Container, latest string) {
+	c.Env = padv1.MergeEnv(c.Env, []corev1.EnvVar{{Name: "PADDLE_CHECKPOINT_RESUME", Value: path.Join(checkpointMountPath, latest)}})
+}
+
+// podCheckpoint returns the checkpoint reported by a trainer pod, or nil if
+// it has not reported any.
+func podCheckpoint(pod *corev1.Pod) (*checkpointReport, error) {
+	value, ok := pod.Annotations[CheckpointAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	r := &checkpointReport{}
+	if err := json.Unmarshal([]byte(value), r); err != nil {
+		return nil, err
+	}
+	if !validCheckpointPath(r.Path) {
+		return nil, fmt.Errorf("checkpoint path %q is not a relative path in the checkpoint directory", r.Path)
+	}
+	return r, nil
+}
+
+// jobCheckpoint returns the checkpoint status of a job from the checkpoints
+// reported by its trainer pods, previous is the last checkpoint status.
+// The checkpoint of the latest pass is recorded at now if it is new.
+func jobCheckpoint(previous *padv1.CheckpointStatus, pods []*corev1.Pod, now time.Time) *padv1.CheckpointStatus {
+	var latest *checkpointReport
+	for _, pod := range pods {
+		r, err := podCheckpoint(pod)
+		if err != nil {
+			log.Warningf("invalid checkpoint of pod namespace=%v name=%v: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		if r != nil && (latest == nil || r.Pass > latest.Pass) {
+			latest = r
+		}
+	}
+	if latest == nil || (previous != nil && previous.Latest == latest.Path) {
+		return previous
+	}
+
+	st := previous.DeepCopy()
+	if st == nil {
+		st = &padv1.CheckpointStatus{}
+	}
+	st.Latest, st.Pass, st.Time = latest.Path, latest.Pass, metav1.NewTime(now)
+	for _, p := range st.Retained {
+		if p == latest.Path {
+			return st
+		}
+	}
+	st.Retained = append(st.Retained, latest.Path)
+	return st
+}
+
+// checkpointGCPod returns a pod deleting the checkpoints expired of job.
+func checkpointGCPod(job *padv1.PaddleJob, expired []string) *corev1.Pod {
+	volume, mount := checkpointVolume(job)
+	command := []string{"rm", "-rf", "--"}
+	for _, p := range expired {
+		command = append(command, path.Join(checkpointMountPath, p))
+	}
+	// The paths are checked by validCheckpointPath, they always marshal.
+	annotation, _ := json.Marshal(expired)
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName:    job.Name + "-checkpoint-gc-",
+			Namespace:       job.Namespace,
+			Labels:          objectLabels(job, checkpointGCRole),
+			Annotations:     map[string]string{expiredCheckpointsAnnotation: string(annotation)},
+			OwnerReferences: []metav1.OwnerReference{ownerReference(job)},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:    corev1.RestartPolicyOnFailure,
+			ImagePullSecrets: job.Spec.ImagePullSecrets,
+			NodeSelector:     job.Spec.NodeSelector,
+			Volumes:          []corev1.Volume{volume},
+			Containers: []corev1.Container{
+				{
+					Name:         "checkpoint-gc",
+					Image:        job.Spec.Image,
+					Command:      command,
+					VolumeMounts: []corev1.VolumeMount{mount},
+				},
+			},
+		},
+	}
+}
+
+// checkpointGCLabels returns the labels the checkpoint collection pods of
+// job are selected by.
+func checkpointGCLabels(job *padv1.PaddleJob) labels.Set {
+	return selectorLabels(job, checkpointGCRole)
+}
+
+// collectedCheckpoints returns the checkpoints out of retained which were
+// not deleted by the succeeded checkpoint collection pod.
+func collectedCheckpoints(retained []string, pod *corev1.Pod) []string {
+	var expired []string
+	if err := json.Unmarshal([]byte(pod.Annotations[expiredCheckpointsAnnotation]), &expired); err != nil {
+		log.Warningf("invalid expired checkpoints of pod namespace=%v name=%v: %v", pod.Namespace, pod.Name, err)
+		return retained
+	}
+	deleted := map[string]bool{}
+	for _, p := range expired {
+		deleted[p] = true
+	}
+	var kept []string
+	for _, p := range retained {
+		if !deleted[p] {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// trainerCheckpoint returns the checkpoint status of the job from its
+// trainers, previous is the last reported status.
+func (updater *PaddleJobUpdater) trainerCheckpoint(previous *padv1.CheckpointStatus) *padv1.CheckpointStatus {
+	pods, err := updater.rolePods(padv1.Trainer)
+	if err != nil {
+		log.Errorf("list trainers of namespace=%v name=%v error: %v", updater.job.Namespace, updater.job.Name, err)
+		return previous
+	}
+	return jobCheckpoint(previous, pods, time.Now())
+}
+
+// collectCheckpoints deletes the checkpoints of the job beyond its
+// retention count with a pod mounting the checkpoint volume. Only one
+// collection runs at a time, the checkpoints are dropped from the retained
+// ones once it succeeded. Checkpoints on a host path are not collected,
+// they are spread over the nodes.
+func (updater *PaddleJobUpdater) collectCheckpoints(status *padv1.PaddleJobStatus) {
+	job := updater.job
+	cp, st := job.Spec.Checkpoint, status.Checkpoint
+	if cp == nil || cp.PersistentVolumeClaim == nil || cp.Retention <= 0 || st == nil || len(st.Retained) <= cp.Retention {
+		return
+	}
+
+	pods, err := updater.podLister.Pods(job.Namespace).List(labels.SelectorFromSet(checkpointGCLabels(job)))
+	if err != nil {
+		log.Errorf("list checkpoint collection pods of namespace=%v name=%v error: %v", job.Namespace, job.Name, err)
+		return
+	}
+	// The previous status may share the checkpoint status.
+	st = st.DeepCopy()
+	status.Checkpoint = st
+	for _, pod := range pods {
+		switch pod.Status.Phase {
+		case corev1.PodSucceeded:
+			st.Retained = collectedCheckpoints(st.Retained, pod)
+		case corev1.PodFailed:
+			// The checkpoints are collected again by the next pod.
+			log.Warningf("checkpoint collection pod namespace=%v name=%v failed", pod.Namespace, pod.Name)
+		default:
+			return
+		}
+		if err := updater.kubeClient.CoreV1().Pods(pod.Namespace).Delete(pod.Name, &metav1.DeleteOptions{}); err != nil {
+			log.Warningf("delete checkpoint collection pod namespace=%v name=%v error: %v", pod.Namespace, pod.Name, err)
+		}
+	}
+
+	if len(st.Retained) <= cp.Retention {
+		return
+	}
+	n := len(st.Retained) - cp.Retention
+	log.Infof("Delete checkpoints %v of PaddleJob namespace=%v name=%v", st.Retained[:n], job.Namespace, job.Name)
+	if _, err := updater.kubeClient.CoreV1().Pods(job.Namespace).Create(checkpointGCPod(job, st.Retained[:n])); err != nil {
+		log.Errorf("create checkpoint collection pod of namespace=%v name=%v error: %v", job.Namespace, job.Name, err)
+	}
+}
+
+// resumeFromCheckpoint makes the pods of every role created by a restart of
+// the job resume from its latest checkpoint.
+func (updater *PaddleJobUpdater) resumeFromCheckpoint() error {
+	job := updater.job
+	st := updater.status.Checkpoint
+	if job.Spec.Checkpoint == nil || st == nil || st.Latest == "" {
+		return nil
+	}
+	log.Infof("Resume PaddleJob namespace=%v name=%v from checkpoint %v", job.Namespace, job.Name, st.Latest)
+	for _, tp := range jobRoles(job) {
+		if tp == padv1.Trainer {
+			// The trainer job is created again from its spec.
+			setResume(padv1.Container(&job.Spec.Trainer.ReplicaSpec.Spec.Template.Spec, roleContainerName(tp)), st.Latest)
+			continue
+		}
+
+		// The replicaset creates the new pods from its template.
+		desired, err := updater.replicaSet(tp)
+		if err != nil {
+			return err
+		}
+		rs, err := updater.kubeClient.ExtensionsV1beta1().ReplicaSets(job.Namespace).Get(desired.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		setResume(padv1.Container(&rs.Spec.Template.Spec, roleContainerName(tp)), st.Latest)
+		setSpecHash(&rs.ObjectMeta, rs.Spec)
+		rs, err = updater.kubeClient.ExtensionsV1beta1().ReplicaSets(job.Namespace).Update(rs)
+		if err != nil {
+			return err
+		}
+		updater.observeGeneration(rs.ObjectMeta)
+		rs.DeepCopyInto(desired)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+func TestJobCheckpoint(t *testing.T) {
+	trainer := func(name, checkpoint string) *corev1.Pod {
+		pod := testPod(name, 1, corev1.PodRunning, true)
+		pod.Annotations = map[string]string{CheckpointAnnotation: checkpoint}
+		return pod
+	}
+	now := time.Unix(1000, 0)
+
+	st := jobCheckpoint(nil, []*corev1.Pod{
+		trainer("a", `{"path": "pass-00001", "pass": 1}`),
+		trainer("b", `{"path": "pass-00002", "pass": 2}`),
+		trainer("c", `{"path": "../../etc", "pass": 9}`),
+	}, now)
+	assert.Equal(t, "pass-00002", st.Latest)
+	assert.Equal(t, 2, st.Pass)
+	assert.Equal(t, now, st.Time.Time)
+	assert.Equal(t, []string{"pass-00002"}, st.Retained)
+
+	// The same checkpoint is not recorded again.
+	assert.Equal(t, st, jobCheckpoint(st, []*corev1.Pod{trainer("b", `{"path": "pass-00002", "pass": 2}`)}, now.Add(time.Minute)))
+
+	next := jobCheckpoint(st, []*corev1.Pod{trainer("b", `{"path": "pass-00003", "pass": 3}`)}, now.Add(time.Minute))
+	assert.Equal(t, []string{"pass-00002", "pass-00003"}, next.Retained)
+	assert.Equal(t, []string{"pass-00002"}, st.Retained)
+
+	job := &padv1.PaddleJob{}
+	job.Name = "mnist"
+	job.Spec.Image = "paddle"
+	job.Spec.Checkpoint = &padv1.CheckpointSpec{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "checkpoints"}}
+	pod := checkpointGCPod(job, next.Retained[:1])
+	assert.Equal(t, []string{"rm", "-rf", "--", "/checkpoint/pass-00002"}, pod.Spec.Containers[0].Command)
+	assert.Equal(t, "mnist", pod.Spec.Containers[0].VolumeMounts[0].SubPath)
+
+	// The checkpoints are dropped once the collection pod succeeded.
+	assert.Equal(t, []string{"pass-00003"}, collectedCheckpoints(next.Retained, pod))
+	pod.Annotations = nil
+	assert.Equal(t, next.Retained, collectedCheckpoints(next.Retained, pod))
+}
+
+func TestParseCheckpoint(t *testing.T) {
+	job := &padv1.PaddleJob{}
+	job.Name = "mnist"
+	job.Spec.Checkpoint = &padv1.CheckpointSpec{
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "checkpoints"},
+		IntervalSeconds:       600,
+	}
+	job.Status.Checkpoint = &padv1.CheckpointStatus{Latest: "pass-00002"}
+	SetDefaults(job, nil)
+	assert.NoError(t, validate(job))
+	p := &DefaultJobParser{}
+
+	for _, spec := range []corev1.PodSpec{p.parseToPserver(job).Spec.Template.Spec, p.parseToTrainer(job).Spec.Template.Spec} {
+		assert.Contains(t, spec.Volumes, corev1.Volume{
+			Name:         "checkpoint",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "checkpoints"}},
+		})
+		c := spec.Containers[0]
+		assert.Contains(t, c.VolumeMounts, corev1.VolumeMount{Name: "checkpoint", MountPath: "/checkpoint", SubPath: "mnist"})
+		env := map[string]string{}
+		for _, e := range c.Env {
+			env[e.Name] = e.Value
+		}
+		assert.Equal(t, "/checkpoint", env["PADDLE_CHECKPOINT_DIR"])
+		assert.Equal(t, "600", env["PADDLE_CHECKPOINT_INTERVAL"])
+		assert.Equal(t, "/checkpoint/pass-00002", env["PADDLE_CHECKPOINT_RESUME"])
+	}
+
+	job.Spec.Checkpoint.Directory = "../other"
+	assert.Error(t, validate(job))
+	job.Spec.Checkpoint = &padv1.CheckpointSpec{}
+	assert.Error(t, validate(job))
+}
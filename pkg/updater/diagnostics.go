@@ -0,0 +1,163 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"fmt"
+
+	log "github.com/golang/glog"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// maxFailureLogBytes bounds the size of the log kept in the status.
+	maxFailureLogBytes = 4096
+	// crashLoopBackOff is the waiting reason of a crash looping container.
+	crashLoopBackOff = "CrashLoopBackOff"
+)
+
+// infrastructureReasons are the pod and container reasons of failures
+// caused by the cluster rather than by the job.
+var infrastructureReasons = map[string]bool{
+	"Evicted":                  true,
+	"NodeLost":                 true,
+	"Preempting":               true,
+	"Shutdown":                 true,
+	"UnexpectedAdmissionError": true,
+	"ContainerCannotRun":       true,
+}
+
+// classifyFailure tells whether a failure with reason and exitCode is
+// caused by the job or by the cluster. A container killed by SIGKILL or
+// SIGTERM without running out of memory has been stopped from outside.
+func classifyFailure(reason string, exitCode *int32) padv1.FailureClass {
+	switch {
+	case infrastructureReasons[reason]:
+		return padv1.FailureClassInfrastructure
+	case reason == "OOMKilled" || reason == "DeadlineExceeded":
+		return padv1.FailureClassUser
+	case exitCode != nil && (*exitCode == 137 || *exitCode == 143):
+		return padv1.FailureClassInfrastructure
+	}
+	return padv1.FailureClassUser
+}
+
+// podFailure returns the failure of a pod of the resource tp with the given
+// rank, or nil if the pod neither failed nor is crash looping.
+func podFailure(pod *corev1.Pod, tp padv1.TrainingResourceType, rank int) *padv1.FailureInfo {
+	cs := roleContainerStatus(pod, roleContainerName(tp))
+	crashLooping := cs != nil && cs.State.Waiting != nil && cs.State.Waiting.Reason == crashLoopBackOff
+	if pod.Status.Phase != corev1.PodFailed && !crashLooping {
+		return nil
+	}
+
+	f := &padv1.FailureInfo{
+		TrainingResourceType: tp,
+		Pod:                  pod.Name,
+		Rank:                 rank,
+		// Evicted, DeadlineExceeded or NodeLost are set on the pod.
+		Reason:  pod.Status.Reason,
+		Message: pod.Status.Message,
+		Time:    metav1.Now(),
+	}
+	if cs != nil {
+		if t := lastTermination(cs); t != nil {
+			code := t.ExitCode
+			f.ExitCode = &code
+			if f.Reason == "" {
+				f.Reason = t.Reason
+			}
+			if f.Message == "" {
+				f.Message = t.Message
+			}
+			if !t.FinishedAt.IsZero() {
+				f.Time = t.FinishedAt
+			}
+		}
+	}
+	if f.Reason == "" && crashLooping {
+		f.Reason = crashLoopBackOff
+	}
+	f.Class = classifyFailure(f.Reason, f.ExitCode)
+	return f
+}
+
+// diagnoseFailure returns the failure of the pod of the job which failed
+// first with the tail of its log, or nil if no pod failed.
+func (updater *PaddleJobUpdater) diagnoseFailure() *padv1.FailureInfo {
+	var first *padv1.FailureInfo
+	var firstPod *corev1.Pod
+	for _, tp := range jobRoles(updater.job) {
+		pods, err := updater.rolePods(tp)
+		if err != nil {
+			log.Errorf("list pods of %v error: %v", tp, err)
+			continue
+		}
+		for rank, pod := range rankPods(pods) {
+			f := podFailure(pod, tp, rank)
+			if f != nil && (first == nil || f.Time.Time.Before(first.Time.Time)) {
+				first, firstPod = f, pod
+			}
+		}
+	}
+	if first == nil {
+		return nil
+	}
+
+	if cs := roleContainerStatus(firstPod, roleContainerName(first.TrainingResourceType)); cs != nil {
+		// The log of a restarted container is the one of its previous run.
+		first.Log = updater.failureLog(firstPod, cs.Name, cs.State.Terminated == nil)
+	}
+	log.Infof("PaddleJob namespace=%v name=%v: %v", updater.job.Namespace, updater.job.Name, failureReason(first))
+	return first
+}
+
+// failureLog returns the last lines of the log of a container.
+func (updater *PaddleJobUpdater) failureLog(pod *corev1.Pod, container string, previous bool) string {
+	lines := updater.config.FailureLogLines
+	if lines <= 0 {
+		return ""
+	}
+	opts := &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &lines,
+		Previous:  previous,
+	}
+	raw, err := updater.kubeClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, opts).Do().Raw()
+	if err != nil {
+		log.Warningf("get log of pod namespace=%v name=%v error: %v", pod.Namespace, pod.Name, err)
+		return ""
+	}
+	if len(raw) > maxFailureLogBytes {
+		raw = raw[len(raw)-maxFailureLogBytes:]
+	}
+	return string(raw)
+}
+
+// failureReason summarizes a failure in the reason of the job status.
+func failureReason(f *padv1.FailureInfo) string {
+	reason := fmt.Sprintf("%v pod %v (rank %d) failed", f.TrainingResourceType, f.Pod, f.Rank)
+	if f.Reason != "" {
+		reason += ": " + f.Reason
+	}
+	if f.ExitCode != nil {
+		reason += fmt.Sprintf(", exit code %d", *f.ExitCode)
+	}
+	return fmt.Sprintf("%v; %v failure", reason, f.Class)
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+func TestPodFailure(t *testing.T) {
+	assert.Nil(t, podFailure(testPod("a", 1, corev1.PodRunning, true), padv1.Trainer, 0))
+
+	oom := testPod("a", 1, corev1.PodFailed, false)
+	oom.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "trainer",
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}},
+	}}
+	f := podFailure(oom, padv1.Trainer, 3)
+	assert.Equal(t, 3, f.Rank)
+	assert.Equal(t, "OOMKilled", f.Reason)
+	assert.Equal(t, int32(137), *f.ExitCode)
+	assert.Equal(t, padv1.FailureClassUser, f.Class)
+
+	evicted := testPod("b", 1, corev1.PodFailed, false)
+	evicted.Status.Reason = "Evicted"
+	evicted.Status.Message = "The node was low on resource: memory."
+	f = podFailure(evicted, padv1.Pserver, 0)
+	assert.Equal(t, "Evicted", f.Reason)
+	assert.Equal(t, padv1.FailureClassInfrastructure, f.Class)
+
+	crashing := testPod("c", 1, corev1.PodRunning, false)
+	crashing.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:                 "trainer",
+		State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error", Message: "bad input"}},
+	}}
+	f = podFailure(crashing, padv1.Trainer, 1)
+	assert.Equal(t, "Error", f.Reason)
+	assert.Equal(t, "bad input", f.Message)
+	assert.Equal(t, padv1.FailureClassUser, f.Class)
+	assert.Equal(t, "TRAINER pod c (rank 1) failed: Error, exit code 1; User failure", failureReason(f))
+}
@@ -0,0 +1,252 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	log "github.com/golang/glog"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SpecHashAnnotation is the annotation of the replicasets and the trainer
+// job of a PaddleJob with the hash of the spec the operator wrote them with.
+const SpecHashAnnotation = "paddlepaddle.org/spec-hash"
+
+// specHash returns the hash of the spec of a child of a job.
+func specHash(spec interface{}) string {
+	// The specs of the children are plain API types, they always marshal.
+	data, _ := json.Marshal(spec)
+	h := fnv.New32a()
+	h.Write(data)
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// setSpecHash stamps the hash of spec on the child with meta.
+func setSpecHash(meta *metav1.ObjectMeta, spec interface{}) {
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[SpecHashAnnotation] = specHash(spec)
+}
+
+// driftReason returns why the child with meta differs from the one the
+// operator wrote with spec, or an empty string if it does not. generation
+// is the generation of the child after the operator last wrote it, 0 if it
+// is unknown. The API server bumps the generation on every change of the
+// spec, so a change by hand is caught even if the annotation is intact.
+func driftReason(meta metav1.ObjectMeta, spec interface{}, generation int64) string {
+	if hash, want := meta.Annotations[SpecHashAnnotation], specHash(spec); hash != want {
+		return fmt.Sprintf("spec hash is %q instead of %q", hash, want)
+	}
+	if generation > 0 && meta.Generation != generation {
+		return fmt.Sprintf("spec was changed, generation %d instead of %d", meta.Generation, generation)
+	}
+	return ""
+}
+
+// setCondition sets the condition tp of status and returns true if its
+// status changed. A condition which is not set yet is only added when it
+// is true.
+func setCondition(status *padv1.PaddleJobStatus, tp padv1.PaddleJobConditionType, cs corev1.ConditionStatus, reason, message string) bool {
+	for i := range status.Conditions {
+		c := &status.Conditions[i]
+		if c.Type != tp {
+			continue
+		}
+		changed := c.Status != cs
+		if changed {
+			c.LastTransitionTime = metav1.Now()
+		}
+		c.Status, c.Reason, c.Message = cs, reason, message
+		return changed
+	}
+	if cs != corev1.ConditionTrue {
+		return false
+	}
+	status.Conditions = append(status.Conditions, padv1.PaddleJobCondition{
+		Type:               tp,
+		Status:             cs,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	})
+	return true
+}
+
+// observeGeneration remembers the generation of a child of the job after
+// the operator wrote it.
+func (updater *PaddleJobUpdater) observeGeneration(meta metav1.ObjectMeta) {
+	if updater.generations == nil {
+		updater.generations = map[string]int64{}
+	}
+	updater.generations[meta.Name] = meta.Generation
+}
+
+// knownGeneration returns the generation of the child with meta after the
+// operator last wrote it. The generations are lost when the operator
+// restarts, a child with the expected hash is then trusted as it is.
+func (updater *PaddleJobUpdater) knownGeneration(meta metav1.ObjectMeta, spec interface{}) int64 {
+	if g, ok := updater.generations[meta.Name]; ok {
+		return g
+	}
+	if meta.Annotations[SpecHashAnnotation] == specHash(spec) {
+		updater.observeGeneration(meta)
+	}
+	return updater.generations[meta.Name]
+}
+
+// driftPolicy returns the drift policy of the job.
+func (updater *PaddleJobUpdater) driftPolicy() padv1.DriftPolicy {
+	if p := updater.job.Spec.DriftPolicy; p != "" {
+		return p
+	}
+	return padv1.DriftPolicyRevert
+}
+
+// syncReplicaSet recreates the replicaset desired if it was deleted, and
+// reverts it if it was changed by hand and the drift policy says so. It
+// returns the drift which is kept.
+func (updater *PaddleJobUpdater) syncReplicaSet(desired *v1beta1.ReplicaSet) (string, error) {
+	client := updater.kubeClient.ExtensionsV1beta1().ReplicaSets(updater.job.Namespace)
+	rs, err := client.Get(desired.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		log.Infof("Recreate deleted replicaset namespace=%v name=%v", updater.job.Namespace, desired.Name)
+		rs = desired.DeepCopy()
+		rs.ResourceVersion, rs.UID = "", ""
+		setSpecHash(&rs.ObjectMeta, desired.Spec)
+		if rs, err = client.Create(rs); err != nil {
+			return "", err
+		}
+		updater.observeGeneration(rs.ObjectMeta)
+		updater.recordEvent(corev1.EventTypeWarning, "Recreated", fmt.Sprintf("replicaset %v was deleted", desired.Name))
+		return "", nil
+	}
+	if err != nil || rs.DeletionTimestamp != nil {
+		return "", err
+	}
+	reason := driftReason(rs.ObjectMeta, desired.Spec, updater.knownGeneration(rs.ObjectMeta, desired.Spec))
+	if reason == "" {
+		return "", nil
+	}
+	reason = fmt.Sprintf("replicaset %v: %v", rs.Name, reason)
+	if updater.driftPolicy() == padv1.DriftPolicyFlag {
+		return reason, nil
+	}
+
+	log.Infof("Revert replicaset namespace=%v name=%v: %v", updater.job.Namespace, rs.Name, reason)
+	rs.Spec.Replicas = desired.Spec.Replicas
+	rs.Spec.MinReadySeconds = desired.Spec.MinReadySeconds
+	rs.Spec.Template = *desired.Spec.Template.DeepCopy()
+	setSpecHash(&rs.ObjectMeta, desired.Spec)
+	if rs, err = client.Update(rs); err != nil {
+		return "", err
+	}
+	updater.observeGeneration(rs.ObjectMeta)
+	updater.recordEvent(corev1.EventTypeWarning, "DriftReverted", reason)
+	return "", nil
+}
+
+// syncTrainerJob recreates the trainer job if it was deleted, and reverts
+// it if it was changed by hand and the drift policy says so. The template
+// of a job cannot change, only its parallelism and deadline are reverted.
+// It returns the drift which is kept.
+func (updater *PaddleJobUpdater) syncTrainerJob() (string, error) {
+	desired := updater.job.Spec.Trainer.ReplicaSpec
+	client := updater.kubeClient.BatchV1().Jobs(updater.job.Namespace)
+	j, err := client.Get(desired.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		log.Infof("Recreate deleted trainer job namespace=%v name=%v", updater.job.Namespace, desired.Name)
+		j = desired.DeepCopy()
+		j.ResourceVersion, j.UID = "", ""
+		setSpecHash(&j.ObjectMeta, desired.Spec)
+		if j, err = client.Create(j); err != nil {
+			return "", err
+		}
+		updater.observeGeneration(j.ObjectMeta)
+		updater.recordEvent(corev1.EventTypeWarning, "Recreated", fmt.Sprintf("trainer job %v was deleted", desired.Name))
+		return "", nil
+	}
+	if err != nil || j.DeletionTimestamp != nil {
+		return "", err
+	}
+	reason := driftReason(j.ObjectMeta, desired.Spec, updater.knownGeneration(j.ObjectMeta, desired.Spec))
+	if reason == "" {
+		return "", nil
+	}
+	reason = fmt.Sprintf("trainer job %v: %v", j.Name, reason)
+	if updater.driftPolicy() == padv1.DriftPolicyFlag {
+		return reason, nil
+	}
+
+	log.Infof("Revert trainer job namespace=%v name=%v: %v", updater.job.Namespace, j.Name, reason)
+	j.Spec.Parallelism = desired.Spec.Parallelism
+	j.Spec.ActiveDeadlineSeconds = desired.Spec.ActiveDeadlineSeconds
+	setSpecHash(&j.ObjectMeta, desired.Spec)
+	if j, err = client.Update(j); err != nil {
+		return "", err
+	}
+	updater.observeGeneration(j.ObjectMeta)
+	updater.recordEvent(corev1.EventTypeWarning, "DriftReverted", reason)
+	return "", nil
+}
+
+// reconcileDrift compares the replicasets and the trainer job of the
+// running job with the ones the operator created. Deleted ones are
+// recreated, the ones changed by hand are reverted or flagged with the
+// Drifted condition according to the drift policy of the job.
+func (updater *PaddleJobUpdater) reconcileDrift() {
+	var drifts []string
+	for _, tp := range []padv1.TrainingResourceType{padv1.Pserver, padv1.Heter, padv1.Evaluator} {
+		desired, err := updater.replicaSet(tp)
+		if err != nil {
+			// The job has no such role.
+			continue
+		}
+		drift, err := updater.syncReplicaSet(desired)
+		if err != nil {
+			log.Errorf("sync replicaset namespace=%v name=%v error: %v", updater.job.Namespace, desired.Name, err)
+		}
+		if drift != "" {
+			drifts = append(drifts, drift)
+		}
+	}
+	drift, err := updater.syncTrainerJob()
+	if err != nil {
+		log.Errorf("sync trainer job namespace=%v name=%v error: %v", updater.job.Namespace, updater.job.Name, err)
+	}
+	if drift != "" {
+		drifts = append(drifts, drift)
+	}
+
+	if len(drifts) == 0 {
+		setCondition(&updater.status, padv1.PaddleJobDrifted, corev1.ConditionFalse, "InSync", "")
+		return
+	}
+	message := strings.Join(drifts, "; ")
+	if setCondition(&updater.status, padv1.PaddleJobDrifted, corev1.ConditionTrue, "ChangedByHand", message) {
+		log.Warningf("PaddleJob namespace=%v name=%v drifted: %v", updater.job.Namespace, updater.job.Name, message)
+		updater.recordEvent(corev1.EventTypeWarning, "Drifted", message)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+func TestDriftReason(t *testing.T) {
+	replicas := int32(2)
+	spec := v1beta1.ReplicaSetSpec{Replicas: &replicas}
+	meta := metav1.ObjectMeta{Name: "mnist-pserver", Generation: 1}
+	setSpecHash(&meta, spec)
+	assert.Equal(t, "", driftReason(meta, spec, 1))
+	// The generation is unknown after a restart of the operator.
+	assert.Equal(t, "", driftReason(meta, spec, 0))
+
+	meta.Generation = 2
+	assert.Contains(t, driftReason(meta, spec, 1), "generation 2 instead of 1")
+
+	changed := int32(3)
+	assert.Contains(t, driftReason(meta, v1beta1.ReplicaSetSpec{Replicas: &changed}, 2), "spec hash")
+}
+
+func TestSetCondition(t *testing.T) {
+	status := &padv1.PaddleJobStatus{}
+	assert.False(t, setCondition(status, padv1.PaddleJobDrifted, corev1.ConditionFalse, "InSync", ""))
+	assert.Empty(t, status.Conditions)
+
+	assert.True(t, setCondition(status, padv1.PaddleJobDrifted, corev1.ConditionTrue, "ChangedByHand", "a"))
+	assert.False(t, setCondition(status, padv1.PaddleJobDrifted, corev1.ConditionTrue, "ChangedByHand", "b"))
+	assert.Equal(t, "b", status.Conditions[0].Message)
+	assert.True(t, setCondition(status, padv1.PaddleJobDrifted, corev1.ConditionFalse, "InSync", ""))
+	assert.Len(t, status.Conditions, 1)
+}
@@ -0,0 +1,347 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/golang/glog"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	endpointsVolumeName = "paddle-endpoints"
+	// endpointsMountPath is where the endpoints ConfigMap of a job is
+	// mounted in its pods.
+	endpointsMountPath = "/etc/paddle/endpoints"
+)
+
+// RankAnnotation is the rank of a running pod among the pods of its role,
+// as in the endpoints of the job. A pod keeps it once annotated.
+const RankAnnotation = "paddlepaddle.org/rank"
+
+// waitForPserversScript waits until PSERVERS pserver endpoints, and HETERS
+// heter endpoints if it is set, are listed in the endpoints ConfigMap and
+// all of them accept connections.
+const waitForPserversScript = `check() {
+  [ -s ` + endpointsMountPath + `/$1 ] || return 1
+  IFS=, read -ra eps < ` + endpointsMountPath + `/$1
+  [ "${#eps[@]}" -ge "$2" ] || return 1
+  for ep in "${eps[@]}"; do
+    timeout 2 bash -c "> /dev/tcp/${ep%:*}/${ep##*:}" 2>/dev/null || return 1
+  done
+}
+until check pserver_endpoints "$PSERVERS" && { [ -z "$HETERS" ] || check heter_endpoints "$HETERS"; }; do
+  echo "waiting for $PSERVERS pservers ${HETERS:+and $HETERS heters}"
+  sleep 2
+done`
+
+// endpoint is a pod of a job listed in its endpoints ConfigMap.
+type endpoint struct {
+	Name        string `json:"name"`
+	IP          string `json:"ip"`
+	Rank        int    `json:"rank"`
+	Ports       []int  `json:"ports,omitempty"`
+	SparsePorts []int  `json:"sparsePorts,omitempty"`
+}
+
+// jobEndpoints is the content of the endpoints.json key of the endpoints
+// ConfigMap of a job.
+type jobEndpoints struct {
+	Job               string     `json:"job"`
+	Namespace         string     `json:"namespace"`
+	Port              int        `json:"port"`
+	PortsNum          int        `json:"portsNum"`
+	PortsNumForSparse int        `json:"portsNumForSparse"`
+	Master            string     `json:"master,omitempty"`
+	Pservers          []endpoint `json:"pservers"`
+	Trainers          []endpoint `json:"trainers"`
+	Heters            []endpoint `json:"heters,omitempty"`
+}
+
+// endpointsName returns the name of the endpoints ConfigMap of job.
+func endpointsName(job *padv1.PaddleJob) string {
+	return job.Name + "-endpoints"
+}
+
+// portRange returns n ports from first.
+func portRange(first, n int) []int {
+	var ports []int
+	for i := 0; i < n; i++ {
+		ports = append(ports, first+i)
+	}
+	return ports
+}
+
+// podRank returns the rank pod is annotated with, if any.
+func podRank(pod *corev1.Pod) (int, bool) {
+	value, ok := pod.Annotations[RankAnnotation]
+	if !ok {
+		return 0, false
+	}
+	rank, err := strconv.Atoi(value)
+	if err != nil || rank < 0 {
+		return 0, false
+	}
+	return rank, true
+}
+
+// assignRanks returns the rank of each pod out of pods by name. A pod keeps
+// the rank it is annotated with, the pods without one take the lowest free
+// ranks by creation time, so the ranks left by the pods which are gone are
+// taken over by their replacements.
+func assignRanks(pods []*corev1.Pod) map[string]int {
+	ranks := map[string]int{}
+	taken := map[int]bool{}
+	var unranked []*corev1.Pod
+	for _, pod := range rankPods(pods) {
+		if rank, ok := podRank(pod); ok && !taken[rank] {
+			ranks[pod.Name] = rank
+			taken[rank] = true
+			continue
+		}
+		unranked = append(unranked, pod)
+	}
+	next := 0
+	for _, pod := range unranked {
+		for taken[next] {
+			next++
+		}
+		ranks[pod.Name] = next
+		taken[next] = true
+	}
+	return ranks
+}
+
+// listEndpoints returns the running pods out of pods which have an IP and
+// are neither terminating nor in released, in the order of their ranks.
+func listEndpoints(job *padv1.PaddleJob, pods []*corev1.Pod, released map[string]bool) []endpoint {
+	var running []*corev1.Pod
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil || released[pod.Name] || pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+		running = append(running, pod)
+	}
+
+	ranks := assignRanks(running)
+	endpoints := []endpoint{}
+	for _, pod := range running {
+		endpoints = append(endpoints, endpoint{
+			Name:        pod.Name,
+			IP:          pod.Status.PodIP,
+			Rank:        ranks[pod.Name],
+			Ports:       portRange(job.Spec.Port, job.Spec.PortsNum),
+			SparsePorts: portRange(job.Spec.Port+job.Spec.PortsNum, job.Spec.PortsNumForSparse),
+		})
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].Rank < endpoints[j].Rank })
+	return endpoints
+}
+
+// staleRanks returns the rank in endpoints of the pods out of pods whose
+// rank annotation differs from it, by pod name.
+func staleRanks(pods []*corev1.Pod, endpoints []endpoint) map[string]int {
+	annotated := map[string]string{}
+	for _, pod := range pods {
+		annotated[pod.Name] = pod.Annotations[RankAnnotation]
+	}
+	stale := map[string]int{}
+	for _, ep := range endpoints {
+		if annotated[ep.Name] != strconv.Itoa(ep.Rank) {
+			stale[ep.Name] = ep.Rank
+		}
+	}
+	return stale
+}
+
+// annotateRanks annotates the pods out of pods with their rank in
+// endpoints. The failures are only logged, the pods are annotated again on
+// the next sync.
+func (updater *PaddleJobUpdater) annotateRanks(pods []*corev1.Pod, endpoints []endpoint) {
+	job := updater.job
+	for name, rank := range staleRanks(pods, endpoints) {
+		patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:"%d"}}}`, RankAnnotation, rank)
+		_, err := updater.kubeClient.CoreV1().Pods(job.Namespace).Patch(name, types.MergePatchType, []byte(patch))
+		if err != nil && !errors.IsNotFound(err) {
+			log.Warningf("annotate rank of pod namespace=%v name=%v error: %v", job.Namespace, name, err)
+		}
+	}
+}
+
+// endpointsData returns the data of the endpoints ConfigMap of job from
+// its pserver, trainer and heter pods. Besides endpoints.json, it has comma
+// separated lists of IPs and of ip:port endpoints for the launch scripts,
+// and the rank of every pod as pod=rank lines.
+func endpointsData(job *padv1.PaddleJob, pservers, trainers, heters []*corev1.Pod, released map[string]bool) map[string]string {
+	e := jobEndpoints{
+		Job:               job.Name,
+		Namespace:         job.Namespace,
+		Port:              job.Spec.Port,
+		PortsNum:          job.Spec.PortsNum,
+		PortsNumForSparse: job.Spec.PortsNumForSparse,
+		Pservers:          listEndpoints(job, pservers, released),
+		Trainers:          listEndpoints(job, trainers, nil),
+	}
+	roles := map[string][]endpoint{"pserver": e.Pservers, "trainer": e.Trainers}
+	if job.Spec.Heter != nil {
+		e.Heters = listEndpoints(job, heters, released)
+		roles["heter"] = e.Heters
+	}
+	if job.Spec.Master != nil {
+		e.Master = masterEndpoint(job)
+	}
+	data := map[string]string{}
+	if content, err := json.MarshalIndent(&e, "", "  "); err == nil {
+		data["endpoints.json"] = string(content)
+	}
+
+	var ranks []string
+	for role, endpoints := range roles {
+		var ips, eps []string
+		for _, ep := range endpoints {
+			ips = append(ips, ep.IP)
+			eps = append(eps, ep.IP+":"+strconv.Itoa(job.Spec.Port))
+			ranks = append(ranks, fmt.Sprintf("%v=%d", ep.Name, ep.Rank))
+		}
+		data[role+"_ips"] = strings.Join(ips, ",")
+		data[role+"_endpoints"] = strings.Join(eps, ",")
+	}
+	sort.Strings(ranks)
+	data["ranks"] = strings.Join(ranks, "\n")
+	return data
+}
+
+// endpointsConfigMap returns the endpoints ConfigMap of job with data.
+func endpointsConfigMap(job *padv1.PaddleJob, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            endpointsName(job),
+			Namespace:       job.Namespace,
+			Labels:          objectLabels(job, ""),
+			OwnerReferences: []metav1.OwnerReference{ownerReference(job)},
+		},
+		Data: data,
+	}
+}
+
+// setEndpoints mounts the endpoints ConfigMap of job in the container c of
+// template.
+func setEndpoints(job *padv1.PaddleJob, template *corev1.PodTemplateSpec, c *corev1.Container) {
+	if !hasVolume(template.Spec.Volumes, endpointsVolumeName) {
+		template.Spec.Volumes = append(template.Spec.Volumes, corev1.Volume{
+			Name: endpointsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: endpointsName(job)},
+				},
+			},
+		})
+	}
+	c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{Name: endpointsVolumeName, MountPath: endpointsMountPath, ReadOnly: true})
+	c.Env = padv1.MergeEnv(c.Env, []corev1.EnvVar{{Name: "PADDLE_ENDPOINTS_DIR", Value: endpointsMountPath}})
+}
+
+// endpointsEnv returns the environment variable name set to the key of the
+// endpoints ConfigMap of job.
+func endpointsEnv(job *padv1.PaddleJob, name, key string) corev1.EnvVar {
+	optional := true
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: endpointsName(job)},
+				Key:                  key,
+				Optional:             &optional,
+			},
+		},
+	}
+}
+
+// waitForPserversContainer returns an init container blocking the trainers
+// of job until all its pservers and heters are listed and reachable, it
+// runs image.
+func waitForPserversContainer(job *padv1.PaddleJob, image string) corev1.Container {
+	env := []corev1.EnvVar{{Name: "PSERVERS", Value: strconv.Itoa(job.Spec.Pserver.MinInstance)}}
+	if job.Spec.Heter != nil {
+		env = append(env, corev1.EnvVar{Name: "HETERS", Value: strconv.Itoa(job.Spec.Heter.Replicas)})
+	}
+	return corev1.Container{
+		Name:    "wait-for-pservers",
+		Image:   image,
+		Command: []string{"bash", "-c", waitForPserversScript},
+		Env:     env,
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: endpointsVolumeName, MountPath: endpointsMountPath, ReadOnly: true},
+		},
+	}
+}
+
+// syncEndpoints creates or updates the endpoints ConfigMap of the job from
+// its current pods.
+func (updater *PaddleJobUpdater) syncEndpoints() error {
+	job := updater.job
+	pservers, err := updater.rolePods(padv1.Pserver)
+	if err != nil {
+		return err
+	}
+	trainers, err := updater.rolePods(padv1.Trainer)
+	if err != nil {
+		return err
+	}
+	var heters []*corev1.Pod
+	if job.Spec.Heter != nil {
+		if heters, err = updater.rolePods(padv1.Heter); err != nil {
+			return err
+		}
+	}
+	for _, pods := range [][]*corev1.Pod{pservers, trainers, heters} {
+		updater.annotateRanks(pods, listEndpoints(job, pods, updater.released))
+	}
+	data := endpointsData(job, pservers, trainers, heters, updater.released)
+	if updater.endpoints != nil && reflect.DeepEqual(updater.endpoints, data) {
+		return nil
+	}
+
+	configMaps := updater.kubeClient.CoreV1().ConfigMaps(job.Namespace)
+	cm, err := configMaps.Get(endpointsName(job), metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		log.Infof("Create endpoints namespace=%v name=%v", job.Namespace, endpointsName(job))
+		_, err = configMaps.Create(endpointsConfigMap(job, data))
+	case err == nil:
+		if reflect.DeepEqual(cm.Data, data) {
+			break
+		}
+		cm.Data = data
+		_, err = configMaps.Update(cm)
+	}
+	if err != nil {
+		return err
+	}
+	updater.endpoints = data
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+func TestEndpointsData(t *testing.T) {
+	job := &padv1.PaddleJob{}
+	job.Name = "mnist"
+	job.Spec.Port, job.Spec.PortsNum, job.Spec.PortsNumForSparse = 7164, 2, 1
+
+	pservers := []*corev1.Pod{
+		testPod("ps-b", 2, corev1.PodRunning, true),
+		testPod("ps-a", 1, corev1.PodRunning, true),
+		testPod("ps-pending", 3, corev1.PodPending, false),
+		testPod("ps-released", 4, corev1.PodRunning, true),
+	}
+	for i, pod := range pservers {
+		pod.Status.PodIP = fmt.Sprintf("10.0.0.%d", i)
+	}
+	trainer := testPod("trainer", 1, corev1.PodRunning, true)
+	trainer.Status.PodIP = "10.0.1.0"
+
+	data := endpointsData(job, pservers, []*corev1.Pod{trainer}, nil, map[string]bool{"ps-released": true})
+	assert.Equal(t, "10.0.0.1,10.0.0.0", data["pserver_ips"])
+	assert.Equal(t, "10.0.0.1:7164,10.0.0.0:7164", data["pserver_endpoints"])
+	assert.Equal(t, "10.0.1.0:7164", data["trainer_endpoints"])
+	assert.Equal(t, "ps-a=0\nps-b=1\ntrainer=0", data["ranks"])
+
+	e := jobEndpoints{}
+	assert.NoError(t, json.Unmarshal([]byte(data["endpoints.json"]), &e))
+	assert.Equal(t, endpoint{Name: "ps-b", IP: "10.0.0.0", Rank: 1, Ports: []int{7164, 7165}, SparsePorts: []int{7166}}, e.Pservers[1])
+}
+
+func TestStaleRanks(t *testing.T) {
+	annotated := &corev1.Pod{}
+	annotated.Name = "mnist-trainer-a"
+	annotated.Annotations = map[string]string{RankAnnotation: "0"}
+	moved := &corev1.Pod{}
+	moved.Name = "mnist-trainer-b"
+	moved.Annotations = map[string]string{RankAnnotation: "2"}
+	fresh := &corev1.Pod{}
+	fresh.Name = "mnist-trainer-c"
+
+	endpoints := []endpoint{{Name: annotated.Name, Rank: 0}, {Name: moved.Name, Rank: 1}, {Name: fresh.Name, Rank: 2}}
+	assert.Equal(t, map[string]int{moved.Name: 1, fresh.Name: 2}, staleRanks([]*corev1.Pod{annotated, moved, fresh}, endpoints))
+}
+
+func TestAssignRanks(t *testing.T) {
+	annotated := func(pod *corev1.Pod, rank string) *corev1.Pod {
+		pod.Annotations = map[string]string{RankAnnotation: rank}
+		return pod
+	}
+	pods := []*corev1.Pod{
+		annotated(testPod("kept", 1, corev1.PodRunning, true), "2"),
+		testPod("replacement", 3, corev1.PodRunning, true),
+		testPod("new", 4, corev1.PodRunning, true),
+		annotated(testPod("duplicate", 2, corev1.PodRunning, true), "2"),
+		annotated(testPod("invalid", 5, corev1.PodRunning, true), "x"),
+	}
+	assert.Equal(t, map[string]int{"kept": 2, "duplicate": 0, "replacement": 1, "new": 3, "invalid": 4}, assignRanks(pods))
+
+	// The trainers which keep running keep their ranks when the first one
+	// is replaced.
+	job := &padv1.PaddleJob{}
+	trainers := []*corev1.Pod{
+		annotated(testPod("trainer-b", 2, corev1.PodRunning, true), "1"),
+		testPod("trainer-c", 3, corev1.PodRunning, true),
+	}
+	for i, pod := range trainers {
+		pod.Status.PodIP = fmt.Sprintf("10.0.1.%d", i)
+	}
+	endpoints := listEndpoints(job, trainers, nil)
+	assert.Equal(t, []string{"trainer-c", "trainer-b"}, []string{endpoints[0].Name, endpoints[1].Name})
+	assert.Equal(t, []int{0, 1}, []int{endpoints[0].Rank, endpoints[1].Rank})
+}
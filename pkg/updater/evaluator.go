@@ -0,0 +1,122 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"fmt"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// validateEvaluator validates the evaluator spec of a job.
+func validateEvaluator(e *padv1.EvaluatorSpec) error {
+	if e != nil && len(e.Command) == 0 {
+		return fmt.Errorf("evaluator needs a command")
+	}
+	return nil
+}
+
+// evaluatorReplicaSet returns the replicaset running the single evaluator
+// pod of job. The evaluator mounts the checkpoint directory read-only, it
+// never writes the checkpoints the trainers resume from.
+func evaluatorReplicaSet(job *padv1.PaddleJob) *v1beta1.ReplicaSet {
+	e := job.Spec.Evaluator
+	template := podTemplate(job, roleContainerName(padv1.Evaluator), e.Template)
+	for k, v := range legacyLabels(job, padv1.Evaluator) {
+		template.Labels[k] = v
+	}
+	template.Spec.HostNetwork = false
+	setScheduling(&template.Spec, e.NodeSelector, e.Tolerations, e.Affinity)
+	c := padv1.Container(&template.Spec, roleContainerName(padv1.Evaluator))
+	e.RoleContainer.ApplyTo(c)
+	if c.Image == "" {
+		c.Image = job.Spec.Image
+	}
+	if len(c.Resources.Requests) == 0 && len(c.Resources.Limits) == 0 {
+		c.Resources = e.Resources
+	}
+	c.VolumeMounts = append(c.VolumeMounts, job.Spec.VolumeMounts...)
+	c.Env = padv1.MergeEnv(c.Env, append(podEnv(job), roleEnv(job, padv1.Evaluator)...))
+	if job.Spec.Checkpoint != nil {
+		volume, mount := checkpointVolume(job)
+		if !hasVolume(template.Spec.Volumes, volume.Name) {
+			template.Spec.Volumes = append(template.Spec.Volumes, volume)
+		}
+		mount.ReadOnly = true
+		c.VolumeMounts = append(c.VolumeMounts, mount)
+		c.Env = padv1.MergeEnv(c.Env, []corev1.EnvVar{{Name: "PADDLE_CHECKPOINT_DIR", Value: checkpointMountPath}})
+	}
+
+	replicas := int32(1)
+	return &v1beta1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      job.Name + "-evaluator",
+			Namespace: job.Namespace,
+			Labels:    objectLabels(job, roleContainerName(padv1.Evaluator)),
+		},
+		Spec: v1beta1.ReplicaSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: roleLabels(job, padv1.Evaluator)},
+			Template: template,
+		},
+	}
+}
+
+// evaluatorFailure returns the failure of the evaluator pods of job which
+// fails the job, or nil if the evaluator does not fail it.
+func evaluatorFailure(job *padv1.PaddleJob, pods []*corev1.Pod) *padv1.FailureInfo {
+	if job.Spec.Evaluator == nil || job.Spec.Evaluator.FailurePolicy != padv1.EvaluatorFailurePolicyFail {
+		return nil
+	}
+	for rank, pod := range rankPods(pods) {
+		if f := podFailure(pod, padv1.Evaluator, rank); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// checkEvaluator fails status if the evaluator of the job failed and its
+// failure policy is Fail. The failures of the evaluator are ignored
+// otherwise, its replicaset restarts it.
+func (updater *PaddleJobUpdater) checkEvaluator(status *padv1.PaddleJobStatus) {
+	if updater.job.Spec.Evaluator == nil {
+		return
+	}
+	pods, err := updater.rolePods(padv1.Evaluator)
+	if err != nil {
+		return
+	}
+	if f := evaluatorFailure(updater.job, pods); f != nil {
+		status.Phase = padv1.PaddleJobPhaseFailed
+		status.Reason = failureReason(f)
+		if status.Failure == nil {
+			status.Failure = f
+		}
+	}
+}
+
+// releaseEvaluator deletes the evaluator pod of the job once the trainers
+// finished.
+func (updater *PaddleJobUpdater) releaseEvaluator() error {
+	if updater.job.Spec.Evaluator == nil {
+		return nil
+	}
+	return updater.releaseResource(padv1.Evaluator)
+}
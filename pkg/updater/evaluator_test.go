@@ -0,0 +1,64 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+func TestParseEvaluator(t *testing.T) {
+	job := &padv1.PaddleJob{}
+	job.Name = "mnist"
+	job.Spec.Image = "paddle"
+	job.Spec.HostNetwork = true
+	job.Spec.Checkpoint = &padv1.CheckpointSpec{
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "checkpoints"},
+	}
+	job.Spec.Evaluator = &padv1.EvaluatorSpec{}
+	SetDefaults(job, nil)
+	assert.Error(t, validate(job))
+	job.Spec.Evaluator.Command = []string{"python", "eval.py"}
+	assert.NoError(t, validate(job))
+
+	rs := evaluatorReplicaSet(job)
+	assert.Equal(t, int32(1), *rs.Spec.Replicas)
+	assert.False(t, rs.Spec.Template.Spec.HostNetwork)
+	assert.Equal(t, "mnist", rs.Spec.Template.Labels["paddle-job-evaluator"])
+	c := rs.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []string{"python", "eval.py"}, c.Command)
+	assert.Equal(t, "paddle", c.Image)
+	assert.Contains(t, c.VolumeMounts, corev1.VolumeMount{Name: checkpointVolumeName, MountPath: checkpointMountPath, SubPath: "mnist", ReadOnly: true})
+
+	crashing := &corev1.Pod{}
+	crashing.Name = "mnist-evaluator-x"
+	crashing.Status.Phase = corev1.PodRunning
+	crashing.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "evaluator",
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: crashLoopBackOff}},
+	}}
+	pods := []*corev1.Pod{crashing}
+	assert.Nil(t, evaluatorFailure(job, pods))
+	job.Spec.Evaluator.FailurePolicy = padv1.EvaluatorFailurePolicyFail
+	f := evaluatorFailure(job, pods)
+	if assert.NotNil(t, f) {
+		assert.Equal(t, padv1.Evaluator, f.TrainingResourceType)
+		assert.Equal(t, crashLoopBackOff, f.Reason)
+	}
+}
@@ -16,69 +16,213 @@ package updater
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
+	"strings"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1beta1 "k8s.io/api/extensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	paddlev1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
 )
 
 const (
 	imagePullPolicy = "Always"
+
+	defaultPort              = 7164
+	defaultPortsNum          = 1
+	defaultPortsNumForSparse = 1
+	defaultImage             = "paddlepaddle/paddlecloud-job"
+	defaultPasses            = 1
 )
 
+// JobDefaults holds the operator configurable defaults of a PaddleJob.
+type JobDefaults struct {
+	// Image is used when the job does not specify one.
+	Image string
+	// Resources is used by pservers and trainers that do not request any resource.
+	Resources corev1.ResourceRequirements
+	// NodeSelector is used when the job does not specify one.
+	NodeSelector map[string]string
+	// SpreadPservers spreads the pservers of a job across nodes if the job
+	// does not set the affinity of its pservers.
+	SpreadPservers bool
+	// PserverFailurePolicy is used when the job does not specify one.
+	PserverFailurePolicy paddlev1.PserverFailurePolicy
+}
+
 // DefaultJobParser implement a basic JobParser.
 type DefaultJobParser struct {
+	// Defaults are filled into the job before it is parsed.
+	Defaults *JobDefaults
+	// AcceleratorResources are the resources counted as accelerator
+	// devices, paddlev1.DefaultAcceleratorResources if empty.
+	AcceleratorResources []corev1.ResourceName
 }
 
-// setDefaultAndValidate updates default values for the added job and validates the fields.
-func setDefaultAndValidate(job *paddlev1.PaddleJob) error {
-	// Fill in default values
-	// FIXME: Need to test. What is the value if specified "omitempty"
+// SetDefaults fills the unset fields of the job spec with the built-in
+// defaults and the operator configurable ones in d, which may be nil.
+// It returns true if the spec has been changed.
+func SetDefaults(job *paddlev1.PaddleJob, d *JobDefaults) bool {
+	if d == nil {
+		d = &JobDefaults{}
+	}
+	old := job.Spec.DeepCopy()
+
 	if job.Spec.Port == 0 {
-		job.Spec.Port = 7164
+		job.Spec.Port = defaultPort
 	}
 	if job.Spec.PortsNum == 0 {
-		job.Spec.PortsNum = 1
+		job.Spec.PortsNum = defaultPortsNum
 	}
 	if job.Spec.PortsNumForSparse == 0 {
-		job.Spec.PortsNumForSparse = 1
+		job.Spec.PortsNumForSparse = defaultPortsNumForSparse
 	}
 	if job.Spec.Image == "" {
-		job.Spec.Image = "paddlepaddle/paddlecloud-job"
+		job.Spec.Image = d.Image
+		if job.Spec.Image == "" {
+			job.Spec.Image = defaultImage
+		}
 	}
 	if job.Spec.Passes == 0 {
-		job.Spec.Passes = 1
+		job.Spec.Passes = defaultPasses
+	}
+	if len(job.Spec.NodeSelector) == 0 && len(d.NodeSelector) > 0 {
+		job.Spec.NodeSelector = make(map[string]string, len(d.NodeSelector))
+		for k, v := range d.NodeSelector {
+			job.Spec.NodeSelector[k] = v
+		}
+	}
+	setDefaultResources(&job.Spec.Pserver.Resources, &d.Resources)
+	setDefaultResources(&job.Spec.Trainer.Resources, &d.Resources)
+	if job.Spec.Heter != nil {
+		setDefaultResources(&job.Spec.Heter.Resources, &d.Resources)
+	}
+	if job.Spec.Trainer.SuccessPolicy == "" {
+		job.Spec.Trainer.SuccessPolicy = paddlev1.SuccessPolicyAllTrainers
+	}
+	if job.Spec.Pserver.FailurePolicy == "" {
+		job.Spec.Pserver.FailurePolicy = d.PserverFailurePolicy
+		if job.Spec.Pserver.FailurePolicy == "" {
+			job.Spec.Pserver.FailurePolicy = paddlev1.PserverFailurePolicyFail
+		}
+	}
+	// The name of a job created with generateName is unknown to the
+	// webhook, the updater fills the affinity in once the job is named.
+	if d.SpreadPservers && job.Name != "" && job.Spec.Pserver.Affinity == nil &&
+		(job.Spec.Pserver.Template == nil || job.Spec.Pserver.Template.Spec.Affinity == nil) {
+		job.Spec.Pserver.Affinity = pserverSpreadAffinity(job)
+	}
+
+	return !reflect.DeepEqual(old, &job.Spec)
+}
+
+// setDefaultResources copies the default requests and limits into r if r does
+// not set them.
+func setDefaultResources(r, d *corev1.ResourceRequirements) {
+	if len(r.Requests) == 0 && len(d.Requests) > 0 {
+		r.Requests = d.Requests.DeepCopy()
+	}
+	if len(r.Limits) == 0 && len(d.Limits) > 0 {
+		r.Limits = d.Limits.DeepCopy()
 	}
+}
+
+// pserverSpreadAffinity prefers to schedule the pservers of job on
+// different nodes, so a node failure loses as few parameters as possible.
+func pserverSpreadAffinity(job *paddlev1.PaddleJob) *corev1.Affinity {
+	return &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{
+					Weight: 100,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{
+							MatchLabels: roleLabels(job, paddlev1.Pserver),
+						},
+						TopologyKey: "kubernetes.io/hostname",
+					},
+				},
+			},
+		},
+	}
+}
+
+// validate validates the fields of a defaulted job.
+func validate(job *paddlev1.PaddleJob) error {
 	// TODO: add validations.(helin)
-	return nil
+	if err := validateSuccessPolicy(job); err != nil {
+		return err
+	}
+	if err := validateEvaluator(job.Spec.Evaluator); err != nil {
+		return err
+	}
+	if heter := job.Spec.Heter; heter != nil && heter.Replicas < 1 {
+		return fmt.Errorf("heter needs at least one replica")
+	}
+	if err := validateCheckpoint(job.Spec.Checkpoint); err != nil {
+		return err
+	}
+	return validateMaster(job.Spec.Master)
 }
 
 // NewPaddleJob generates a whole structure of PaddleJob
 func (p *DefaultJobParser) NewPaddleJob(job *paddlev1.PaddleJob) (*paddlev1.PaddleJob, error) {
-	if err := setDefaultAndValidate(job); err != nil {
+	SetDefaults(job, p.Defaults)
+	if err := validate(job); err != nil {
 		return nil, err
 	}
 
 	useHostNetwork := job.Spec.HostNetwork
-	job.Spec.Pserver.ReplicaSpec = parseToPserver(job)
-	job.Spec.Trainer.ReplicaSpec = parseToTrainer(job)
+	job.Spec.Pserver.ReplicaSpec = p.parseToPserver(job)
+	job.Spec.Trainer.ReplicaSpec = p.parseToTrainer(job)
+	if job.Spec.Heter != nil {
+		job.Spec.Heter.ReplicaSpec = p.parseToHeter(job)
+	}
+	if job.Spec.Evaluator != nil {
+		job.Spec.Evaluator.ReplicaSpec = evaluatorReplicaSet(job)
+	}
 	if useHostNetwork {
 		job.Spec.Pserver.ReplicaSpec.Spec.Template.Spec.HostNetwork = true
 		job.Spec.Trainer.ReplicaSpec.Spec.Template.Spec.HostNetwork = true
+		if job.Spec.Heter != nil {
+			job.Spec.Heter.ReplicaSpec.Spec.Template.Spec.HostNetwork = true
+		}
 	}
 	return job, nil
 }
 
 // parseToPserver generate a pserver replicaset resource according to "PaddleJob" resource specs.
-func parseToPserver(job *paddlev1.PaddleJob) *v1beta1.ReplicaSet {
+func (p *DefaultJobParser) parseToPserver(job *paddlev1.PaddleJob) *v1beta1.ReplicaSet {
 	replicas := int32(job.Spec.Pserver.MinInstance)
 	var command []string
 	// FIXME: refine these part.(typhoonzero)
 	command = []string{"paddle_k8s", "start_pserver"}
 
+	template := podTemplate(job, roleContainerName(paddlev1.Pserver), job.Spec.Pserver.Template)
+	for k, v := range legacyLabels(job, paddlev1.Pserver) {
+		template.Labels[k] = v
+	}
+	setScheduling(&template.Spec, job.Spec.Pserver.NodeSelector, job.Spec.Pserver.Tolerations, job.Spec.Pserver.Affinity)
+	c := paddlev1.Container(&template.Spec, "pserver")
+	job.Spec.Pserver.RoleContainer.ApplyTo(c)
+	if c.Image == "" {
+		c.Image = job.Spec.Image
+	}
+	if len(c.Command) == 0 {
+		c.Command = command
+	}
+	if len(c.Resources.Requests) == 0 && len(c.Resources.Limits) == 0 {
+		c.Resources = job.Spec.Pserver.Resources
+	}
+	c.Ports = append(c.Ports, podPorts(job)...)
+	c.Env = paddlev1.MergeEnv(c.Env, append(podEnv(job), p.resourceEnv(&c.Resources)...))
+	c.Env = paddlev1.MergeEnv(c.Env, roleEnv(job, paddlev1.Pserver))
+	setCheckpoint(job, &template, c)
+	setEndpoints(job, &template, c)
+
 	return &v1beta1.ReplicaSet{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "extensions/v1beta1",
@@ -87,39 +231,104 @@ func parseToPserver(job *paddlev1.PaddleJob) *v1beta1.ReplicaSet {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      job.ObjectMeta.Name + "-pserver",
 			Namespace: job.ObjectMeta.Namespace,
+			Labels:    objectLabels(job, roleContainerName(paddlev1.Pserver)),
 		},
 		Spec: v1beta1.ReplicaSetSpec{
 			Replicas: &replicas,
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{"paddle-job-pserver": job.ObjectMeta.Name},
-				},
-				Spec: corev1.PodSpec{
-					Volumes: job.Spec.Volumes,
-					Containers: []corev1.Container{
-						corev1.Container{
-							Name:      "pserver",
-							Image:     job.Spec.Image,
-							Ports:     podPorts(job),
-							Env:       podEnv(job),
-							Command:   command,
-							Resources: job.Spec.Pserver.Resources,
-						},
-					},
-					NodeSelector: job.Spec.NodeSelector,
-				},
-			},
+			Selector: &metav1.LabelSelector{MatchLabels: roleLabels(job, paddlev1.Pserver)},
+			Template: template,
+		},
+	}
+}
+
+// parseToHeter generates the heter replicaset according to the heter spec
+// of the job. The heters are servers of the trainers like the pservers.
+func (p *DefaultJobParser) parseToHeter(job *paddlev1.PaddleJob) *v1beta1.ReplicaSet {
+	replicas := int32(job.Spec.Heter.Replicas)
+
+	template := podTemplate(job, roleContainerName(paddlev1.Heter), job.Spec.Heter.Template)
+	for k, v := range legacyLabels(job, paddlev1.Heter) {
+		template.Labels[k] = v
+	}
+	setScheduling(&template.Spec, job.Spec.Heter.NodeSelector, job.Spec.Heter.Tolerations, job.Spec.Heter.Affinity)
+	c := paddlev1.Container(&template.Spec, "heter")
+	job.Spec.Heter.RoleContainer.ApplyTo(c)
+	if c.Image == "" {
+		c.Image = job.Spec.Image
+	}
+	if len(c.Command) == 0 {
+		c.Command = []string{"paddle_k8s", "start_heter"}
+	}
+	if len(c.Resources.Requests) == 0 && len(c.Resources.Limits) == 0 {
+		c.Resources = job.Spec.Heter.Resources
+	}
+	c.VolumeMounts = append(c.VolumeMounts, job.Spec.VolumeMounts...)
+	c.Ports = append(c.Ports, podPorts(job)...)
+	c.Env = paddlev1.MergeEnv(c.Env, append(podEnv(job), p.resourceEnv(&c.Resources)...))
+	c.Env = paddlev1.MergeEnv(c.Env, roleEnv(job, paddlev1.Heter))
+	setCheckpoint(job, &template, c)
+	setEndpoints(job, &template, c)
+
+	return &v1beta1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      job.ObjectMeta.Name + "-heter",
+			Namespace: job.ObjectMeta.Namespace,
+			Labels:    objectLabels(job, roleContainerName(paddlev1.Heter)),
+		},
+		Spec: v1beta1.ReplicaSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: roleLabels(job, paddlev1.Heter)},
+			Template: template,
 		},
 	}
 }
 
 // parseToTrainer parse PaddleJob to a kubernetes job resource.
-func parseToTrainer(job *paddlev1.PaddleJob) *batchv1.Job {
+func (p *DefaultJobParser) parseToTrainer(job *paddlev1.PaddleJob) *batchv1.Job {
 	replicas := int32(job.Spec.Trainer.MinInstance)
 	var command []string
 	command = []string{"paddle_k8s", "start_trainer", "v2"}
 
-	return &batchv1.Job{
+	template := podTemplate(job, roleContainerName(paddlev1.Trainer), job.Spec.Trainer.Template)
+	for k, v := range legacyLabels(job, paddlev1.Trainer) {
+		template.Labels[k] = v
+	}
+	setScheduling(&template.Spec, job.Spec.Trainer.NodeSelector, job.Spec.Trainer.Tolerations, job.Spec.Trainer.Affinity)
+	c := paddlev1.Container(&template.Spec, "trainer")
+	job.Spec.Trainer.RoleContainer.ApplyTo(c)
+	if c.Image == "" {
+		c.Image = job.Spec.Image
+	}
+	if c.ImagePullPolicy == "" {
+		c.ImagePullPolicy = imagePullPolicy
+	}
+	if len(c.Command) == 0 {
+		c.Command = command
+	}
+	if len(c.Resources.Requests) == 0 && len(c.Resources.Limits) == 0 {
+		c.Resources = job.Spec.Trainer.Resources
+	}
+	c.VolumeMounts = append(c.VolumeMounts, job.Spec.VolumeMounts...)
+	c.Ports = append(c.Ports, podPorts(job)...)
+	c.Env = paddlev1.MergeEnv(c.Env, append(podEnv(job), p.resourceEnv(&c.Resources)...))
+	c.Env = paddlev1.MergeEnv(c.Env, roleEnv(job, paddlev1.Trainer))
+	setCheckpoint(job, &template, c)
+	setEndpoints(job, &template, c)
+	if job.Spec.WaitForPservers {
+		template.Spec.InitContainers = append(template.Spec.InitContainers, waitForPserversContainer(job, c.Image))
+	}
+	if job.Spec.Master != nil {
+		c.Env = paddlev1.MergeEnv(c.Env, []corev1.EnvVar{{Name: "PADDLE_MASTER_ENDPOINT", Value: masterEndpoint(job)}})
+	}
+
+	if job.Spec.Trainer.RestartPolicy != "" {
+		template.Spec.RestartPolicy = job.Spec.Trainer.RestartPolicy
+	}
+	if template.Spec.RestartPolicy == "" || template.Spec.RestartPolicy == corev1.RestartPolicyAlways {
+		template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+
+	trainer := &batchv1.Job{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Job",
 			APIVersion: "batch/v1",
@@ -127,33 +336,81 @@ func parseToTrainer(job *paddlev1.PaddleJob) *batchv1.Job {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      job.ObjectMeta.Name + "-trainer",
 			Namespace: job.ObjectMeta.Namespace,
+			Labels:    objectLabels(job, roleContainerName(paddlev1.Trainer)),
 		},
 		Spec: batchv1.JobSpec{
 			Parallelism: &replicas,
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{"paddle-job": job.ObjectMeta.Name},
-				},
-				Spec: corev1.PodSpec{
-					Volumes: job.Spec.Volumes,
-					Containers: []corev1.Container{
-						corev1.Container{
-							Name:            "trainer",
-							Image:           job.Spec.Image,
-							ImagePullPolicy: imagePullPolicy,
-							Command:         command,
-							VolumeMounts:    job.Spec.VolumeMounts,
-							Ports:           podPorts(job),
-							Env:             podEnv(job),
-							Resources:       job.Spec.Trainer.Resources,
-						},
-					},
-					RestartPolicy: "Never",
-					NodeSelector:  job.Spec.NodeSelector,
-				},
-			},
+			Template:    template,
 		},
 	}
+	// The trainer job replaces the failed trainers the job tolerates.
+	if n := int32(maxTrainerFailures(job)); n > 0 {
+		trainer.Spec.BackoffLimit = &n
+	}
+	return trainer
+}
+
+// podTemplate returns a copy of the role template, or an empty one if it
+// is nil, completed with the labels of the pods running role and the job
+// wide volumes, node selector, network, image pull secrets and service
+// account. The labels of the role template take precedence over the ones
+// of the PaddleJob, the standard labels over both.
+func podTemplate(job *paddlev1.PaddleJob, role string, roleTemplate *corev1.PodTemplateSpec) corev1.PodTemplateSpec {
+	template := corev1.PodTemplateSpec{}
+	if roleTemplate != nil {
+		roleTemplate.DeepCopyInto(&template)
+	}
+	template.Labels = labels.Merge(labels.Merge(job.Labels, template.Labels), standardLabels(job, role))
+	for _, v := range job.Spec.Volumes {
+		if !hasVolume(template.Spec.Volumes, v.Name) {
+			template.Spec.Volumes = append(template.Spec.Volumes, v)
+		}
+	}
+	if len(template.Spec.NodeSelector) == 0 {
+		template.Spec.NodeSelector = job.Spec.NodeSelector
+	}
+	template.Spec.HostNetwork = template.Spec.HostNetwork || job.Spec.HostNetwork
+	for _, secret := range job.Spec.ImagePullSecrets {
+		if !hasImagePullSecret(template.Spec.ImagePullSecrets, secret.Name) {
+			template.Spec.ImagePullSecrets = append(template.Spec.ImagePullSecrets, secret)
+		}
+	}
+	if template.Spec.ServiceAccountName == "" {
+		template.Spec.ServiceAccountName = job.Spec.ServiceAccountName
+	}
+	return template
+}
+
+// setScheduling applies the scheduling constraints of a role to spec. The
+// role node selector and affinity replace the ones of the template and the
+// job, the role tolerations are added to the ones of the template.
+func setScheduling(spec *corev1.PodSpec, nodeSelector map[string]string,
+	tolerations []corev1.Toleration, affinity *corev1.Affinity) {
+	if len(nodeSelector) > 0 {
+		spec.NodeSelector = nodeSelector
+	}
+	spec.Tolerations = append(spec.Tolerations, tolerations...)
+	if affinity != nil {
+		spec.Affinity = affinity.DeepCopy()
+	}
+}
+
+func hasVolume(volumes []corev1.Volume, name string) bool {
+	for _, v := range volumes {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasImagePullSecret(secrets []corev1.LocalObjectReference, name string) bool {
+	for _, s := range secrets {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
 }
 
 // general functions that pserver, trainer use the same
@@ -162,30 +419,22 @@ func podPorts(job *paddlev1.PaddleJob) []corev1.ContainerPort {
 	ports := make([]corev1.ContainerPort, 0)
 	basePort := int32(job.Spec.Port)
 	for i := 0; i < portsTotal; i++ {
-		ports = append(ports, corev1.ContainerPort{
+		port := corev1.ContainerPort{
 			Name:          fmt.Sprintf("jobport-%d", basePort),
 			ContainerPort: basePort,
-		})
+		}
+		// Declared host ports keep the pods away from the nodes where
+		// the ports are taken.
+		if job.Spec.HostNetwork {
+			port.HostPort = basePort
+		}
+		ports = append(ports, port)
 		basePort++
 	}
 	return ports
 }
 
 func podEnv(job *paddlev1.PaddleJob) []corev1.EnvVar {
-	needGPU := "0"
-	if job.NeedGPU() {
-		needGPU = "1"
-	}
-	trainerCount := 1
-	if job.NeedGPU() {
-		q := job.Spec.Trainer.Resources.Requests.NvidiaGPU()
-		trainerCount = int(q.Value())
-	} else {
-		q := job.Spec.Trainer.Resources.Requests.Cpu()
-		// FIXME: CPU resource value can be less than 1.
-		trainerCount = int(q.Value())
-	}
-
 	return []corev1.EnvVar{
 		corev1.EnvVar{Name: "PADDLE_JOB_NAME", Value: job.ObjectMeta.Name},
 		// NOTICE: TRAINERS, PSERVERS, PADDLE_INIT_NUM_GRADIENT_SERVERS
@@ -198,20 +447,23 @@ func podEnv(job *paddlev1.PaddleJob) []corev1.EnvVar {
 		corev1.EnvVar{Name: "TOPOLOGY", Value: job.Spec.Trainer.Entrypoint},
 		corev1.EnvVar{Name: "TRAINER_PACKAGE", Value: job.Spec.Trainer.Workspace},
 		corev1.EnvVar{Name: "PADDLE_INIT_PORT", Value: strconv.Itoa(job.Spec.Port)},
-		// PADDLE_INIT_TRAINER_COUNT should be same to gpu number when use gpu
-		// and cpu cores when using cpu
-		corev1.EnvVar{Name: "PADDLE_INIT_TRAINER_COUNT", Value: strconv.Itoa(trainerCount)},
 		corev1.EnvVar{Name: "PADDLE_INIT_PORTS_NUM", Value: strconv.Itoa(job.Spec.PortsNum)},
 		corev1.EnvVar{Name: "PADDLE_INIT_PORTS_NUM_FOR_SPARSE", Value: strconv.Itoa(job.Spec.PortsNumForSparse)},
 		corev1.EnvVar{Name: "PADDLE_INIT_NUM_GRADIENT_SERVERS", Value: strconv.Itoa(job.Spec.Trainer.MinInstance)},
 		corev1.EnvVar{Name: "PADDLE_INIT_NUM_PASSES", Value: strconv.Itoa(job.Spec.Passes)},
-		corev1.EnvVar{Name: "PADDLE_INIT_USE_GPU", Value: needGPU},
+		corev1.EnvVar{Name: "PADDLE_PSERVER_SERVICE", Value: roleServiceName(job, paddlev1.Pserver)},
+		corev1.EnvVar{Name: "PADDLE_TRAINER_SERVICE", Value: roleServiceName(job, paddlev1.Trainer)},
 		corev1.EnvVar{Name: "LD_LIBRARY_PATH", Value: "/usr/local/cuda/lib64"},
 		corev1.EnvVar{Name: "NAMESPACE", ValueFrom: &corev1.EnvVarSource{
 			FieldRef: &corev1.ObjectFieldSelector{
 				FieldPath: "metadata.namespace",
 			},
 		}},
+		corev1.EnvVar{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{
+				FieldPath: "metadata.name",
+			},
+		}},
 		corev1.EnvVar{Name: "POD_IP", ValueFrom: &corev1.EnvVarSource{
 			FieldRef: &corev1.ObjectFieldSelector{
 				FieldPath: "status.podIP",
@@ -220,4 +472,93 @@ func podEnv(job *paddlev1.PaddleJob) []corev1.EnvVar {
 	}
 }
 
+// trainingRoles are the TRAINING_ROLE of the resources.
+var trainingRoles = map[paddlev1.TrainingResourceType]string{
+	paddlev1.Pserver:   "PSERVER",
+	paddlev1.Trainer:   "TRAINER",
+	paddlev1.Heter:     "HETER_TRAINER",
+	paddlev1.Evaluator: "EVALUATOR",
+}
+
+// roleEnv returns the environment telling the container of the resource tp
+// its role. The containers of a job with heters also get the endpoints of
+// all the roles from the endpoints ConfigMap when they start.
+func roleEnv(job *paddlev1.PaddleJob, tp paddlev1.TrainingResourceType) []corev1.EnvVar {
+	env := []corev1.EnvVar{{Name: "TRAINING_ROLE", Value: trainingRoles[tp]}}
+	if job.Spec.Heter == nil {
+		return env
+	}
+	return append(env,
+		endpointsEnv(job, "PADDLE_PSERVERS_IP_PORT_LIST", "pserver_endpoints"),
+		endpointsEnv(job, "PADDLE_TRAINER_ENDPOINTS", "trainer_endpoints"),
+		endpointsEnv(job, "PADDLE_HETER_TRAINER_IP_PORT_LIST", "heter_endpoints"),
+		corev1.EnvVar{Name: "PADDLE_HETER_TRAINER_NUM", Value: strconv.Itoa(job.Spec.Heter.Replicas)},
+	)
+}
+
+// resourceEnv returns the environment depending on the resources r of a
+// role container. PADDLE_INIT_TRAINER_COUNT should be same to the number of
+// accelerator devices when using them and the number of cpu cores otherwise.
+// The GPU variables are only set for the NVIDIA GPUs, the other accelerators
+// are only counted in PADDLE_ACCELERATOR_DEVICES.
+func (p *DefaultJobParser) resourceEnv(r *corev1.ResourceRequirements) []corev1.EnvVar {
+	accelerators := p.AcceleratorResources
+	if len(accelerators) == 0 {
+		accelerators = paddlev1.DefaultAcceleratorResources
+	}
+	devices := paddlev1.Devices(r, accelerators)
+	if devices == 0 {
+		return []corev1.EnvVar{
+			{Name: "PADDLE_INIT_TRAINER_COUNT", Value: strconv.Itoa(cpuThreads(r))},
+			{Name: "PADDLE_INIT_USE_GPU", Value: "0"},
+		}
+	}
+
+	env := []corev1.EnvVar{
+		{Name: "PADDLE_INIT_TRAINER_COUNT", Value: strconv.Itoa(devices)},
+		{Name: "PADDLE_ACCELERATOR_DEVICES", Value: strconv.Itoa(devices)},
+	}
+	gpus := paddlev1.Devices(r, nvidiaResources(accelerators))
+	if gpus == 0 {
+		return append(env, corev1.EnvVar{Name: "PADDLE_INIT_USE_GPU", Value: "0"})
+	}
+	visible := make([]string, gpus)
+	for i := range visible {
+		visible[i] = strconv.Itoa(i)
+	}
+	return append(env,
+		corev1.EnvVar{Name: "PADDLE_INIT_USE_GPU", Value: "1"},
+		// The device plugin exposes the allocated devices from index 0.
+		corev1.EnvVar{Name: "CUDA_VISIBLE_DEVICES", Value: strings.Join(visible, ",")},
+	)
+}
+
+// nvidiaResources returns the NVIDIA GPU resources out of names.
+func nvidiaResources(names []corev1.ResourceName) []corev1.ResourceName {
+	var gpus []corev1.ResourceName
+	for _, name := range names {
+		if name == paddlev1.ResourceNvidiaGPU || name == paddlev1.ResourceAlphaNvidiaGPU {
+			gpus = append(gpus, name)
+		}
+	}
+	return gpus
+}
+
+// cpuThreads returns the number of threads fitting the cpu of r, the cpu
+// request rounded up or the limit if there is no request, at least 1.
+func cpuThreads(r *corev1.ResourceRequirements) int {
+	q, ok := r.Requests[corev1.ResourceCPU]
+	if !ok {
+		q, ok = r.Limits[corev1.ResourceCPU]
+	}
+	if !ok {
+		return 1
+	}
+	threads := int((q.MilliValue() + 999) / 1000)
+	if threads < 1 {
+		threads = 1
+	}
+	return threads
+}
+
 // general functions end
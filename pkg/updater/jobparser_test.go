@@ -0,0 +1,220 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	paddlev1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+func TestSetDefaults(t *testing.T) {
+	cpu := resource.MustParse("1")
+	d := &JobDefaults{
+		Image:        "myrepo/paddle",
+		Resources:    corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: cpu}},
+		NodeSelector: map[string]string{"pool": "paddle"},
+	}
+
+	job := &paddlev1.PaddleJob{}
+	job.Spec.Trainer.Resources.Requests = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}
+	assert.True(t, SetDefaults(job, d))
+	assert.Equal(t, defaultPort, job.Spec.Port)
+	assert.Equal(t, defaultPortsNum, job.Spec.PortsNum)
+	assert.Equal(t, defaultPortsNumForSparse, job.Spec.PortsNumForSparse)
+	assert.Equal(t, defaultPasses, job.Spec.Passes)
+	assert.Equal(t, "myrepo/paddle", job.Spec.Image)
+	assert.Equal(t, "paddle", job.Spec.NodeSelector["pool"])
+	assert.Equal(t, int64(1), job.Spec.Pserver.Resources.Requests.Cpu().Value())
+	assert.Equal(t, int64(2), job.Spec.Trainer.Resources.Requests.Cpu().Value())
+
+	// A defaulted job has nothing left to default.
+	assert.False(t, SetDefaults(job, d))
+
+	job = &paddlev1.PaddleJob{}
+	SetDefaults(job, nil)
+	assert.Equal(t, defaultImage, job.Spec.Image)
+	assert.Nil(t, job.Spec.NodeSelector)
+}
+
+func TestParseScheduling(t *testing.T) {
+	p := &DefaultJobParser{}
+	job := &paddlev1.PaddleJob{}
+	job.Name = "mnist"
+	job.Spec.NodeSelector = map[string]string{"pool": "paddle"}
+	job.Spec.Trainer.NodeSelector = map[string]string{"gpu": "p100"}
+	job.Spec.Trainer.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}
+	SetDefaults(job, &JobDefaults{SpreadPservers: true})
+
+	pserver := p.parseToPserver(job).Spec.Template.Spec
+	assert.Equal(t, "paddle", pserver.NodeSelector["pool"])
+	assert.Equal(t, map[string]string(roleLabels(job, paddlev1.Pserver)), pserver.Affinity.PodAntiAffinity.
+		PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm.LabelSelector.MatchLabels)
+
+	trainer := p.parseToTrainer(job).Spec.Template.Spec
+	assert.Equal(t, map[string]string{"gpu": "p100"}, trainer.NodeSelector)
+	assert.Len(t, trainer.Tolerations, 1)
+	assert.Nil(t, trainer.Affinity)
+
+	// A job setting the affinity of its pservers is not spread.
+	job = &paddlev1.PaddleJob{}
+	job.Name = "mnist"
+	job.Spec.Pserver.Affinity = &corev1.Affinity{}
+	SetDefaults(job, &JobDefaults{SpreadPservers: true})
+	assert.Nil(t, job.Spec.Pserver.Affinity.PodAntiAffinity)
+}
+
+func TestParseRoleContainer(t *testing.T) {
+	p := &DefaultJobParser{}
+	job := &paddlev1.PaddleJob{}
+	job.Name = "mnist"
+	job.Spec.Trainer.MinInstance = 2
+	job.Spec.Trainer.Image = "myrepo/trainer"
+	job.Spec.Trainer.Command = []string{"/launch.sh"}
+	job.Spec.Trainer.Env = []corev1.EnvVar{
+		{Name: "GLOG_v", Value: "3"},
+		{Name: "TRAINERS", Value: "100"},
+	}
+	SetDefaults(job, nil)
+
+	c := p.parseToTrainer(job).Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "myrepo/trainer", c.Image)
+	assert.Equal(t, []string{"/launch.sh"}, c.Command)
+	assert.Equal(t, corev1.PullPolicy(imagePullPolicy), c.ImagePullPolicy)
+
+	env := map[string]string{}
+	for _, e := range c.Env {
+		_, dup := env[e.Name]
+		assert.False(t, dup, e.Name)
+		env[e.Name] = e.Value
+	}
+	assert.Equal(t, "3", env["GLOG_v"])
+	// The operator generated environment wins.
+	assert.Equal(t, "2", env["TRAINERS"])
+
+	c = p.parseToPserver(job).Spec.Template.Spec.Containers[0]
+	assert.Equal(t, defaultImage, c.Image)
+	assert.Equal(t, []string{"paddle_k8s", "start_pserver"}, c.Command)
+}
+
+func TestParseImagePullSecrets(t *testing.T) {
+	p := &DefaultJobParser{}
+	job := &paddlev1.PaddleJob{}
+	job.Name = "mnist"
+	job.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+	job.Spec.ServiceAccountName = "paddle"
+	job.Spec.Trainer.Template = &corev1.PodTemplateSpec{}
+	job.Spec.Trainer.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+	job.Spec.Trainer.Template.Spec.ServiceAccountName = "trainer"
+	SetDefaults(job, nil)
+
+	pserver := p.parseToPserver(job).Spec.Template.Spec
+	assert.Equal(t, job.Spec.ImagePullSecrets, pserver.ImagePullSecrets)
+	assert.Equal(t, "paddle", pserver.ServiceAccountName)
+
+	trainer := p.parseToTrainer(job).Spec.Template.Spec
+	assert.Len(t, trainer.ImagePullSecrets, 1)
+	assert.Equal(t, "trainer", trainer.ServiceAccountName)
+}
+
+func TestParseResourceEnv(t *testing.T) {
+	job := &paddlev1.PaddleJob{}
+	job.Name = "mnist"
+	job.Spec.Pserver.Resources.Requests = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}
+	job.Spec.Trainer.Resources.Limits = corev1.ResourceList{
+		corev1.ResourceCPU:         resource.MustParse("2500m"),
+		paddlev1.ResourceNvidiaGPU: resource.MustParse("2"),
+	}
+	SetDefaults(job, nil)
+	p := &DefaultJobParser{}
+
+	env := func(c corev1.Container) map[string]string {
+		m := map[string]string{}
+		for _, e := range c.Env {
+			m[e.Name] = e.Value
+		}
+		return m
+	}
+
+	trainer := env(p.parseToTrainer(job).Spec.Template.Spec.Containers[0])
+	assert.Equal(t, "2", trainer["PADDLE_INIT_TRAINER_COUNT"])
+	assert.Equal(t, "1", trainer["PADDLE_INIT_USE_GPU"])
+	assert.Equal(t, "0,1", trainer["CUDA_VISIBLE_DEVICES"])
+	assert.Equal(t, "2", trainer["PADDLE_ACCELERATOR_DEVICES"])
+
+	// A fractional cpu request is rounded up.
+	pserver := env(p.parseToPserver(job).Spec.Template.Spec.Containers[0])
+	assert.Equal(t, "1", pserver["PADDLE_INIT_TRAINER_COUNT"])
+	assert.Equal(t, "0", pserver["PADDLE_INIT_USE_GPU"])
+
+	// Only the configured accelerators are counted.
+	p.AcceleratorResources = []corev1.ResourceName{"example.com/tpu"}
+	trainer = env(p.parseToTrainer(job).Spec.Template.Spec.Containers[0])
+	assert.Equal(t, "3", trainer["PADDLE_INIT_TRAINER_COUNT"])
+	assert.Equal(t, "0", trainer["PADDLE_INIT_USE_GPU"])
+
+	// Other accelerators are counted without the GPU variables.
+	job.Spec.Trainer.Resources.Limits = corev1.ResourceList{"example.com/tpu": resource.MustParse("4")}
+	trainer = env(p.parseToTrainer(job).Spec.Template.Spec.Containers[0])
+	assert.Equal(t, "4", trainer["PADDLE_INIT_TRAINER_COUNT"])
+	assert.Equal(t, "4", trainer["PADDLE_ACCELERATOR_DEVICES"])
+	assert.Equal(t, "0", trainer["PADDLE_INIT_USE_GPU"])
+	_, ok := trainer["CUDA_VISIBLE_DEVICES"]
+	assert.False(t, ok)
+}
+
+func TestParseHeter(t *testing.T) {
+	job := &paddlev1.PaddleJob{}
+	job.Name = "ctr"
+	job.Spec.Image = "paddle"
+	job.Spec.Heter = &paddlev1.HeterSpec{Replicas: 2}
+	SetDefaults(job, nil)
+	assert.NoError(t, validate(job))
+	p := &DefaultJobParser{}
+
+	// The replicaset of an unparsed job is missing, not nil.
+	_, err := (&PaddleJobUpdater{job: job}).replicaSet(paddlev1.Heter)
+	assert.Error(t, err)
+	_, err = (&PaddleJobUpdater{job: job}).replicaSet(paddlev1.Evaluator)
+	assert.Error(t, err)
+
+	rs := p.parseToHeter(job)
+	assert.Equal(t, "ctr-heter", rs.Name)
+	assert.Equal(t, int32(2), *rs.Spec.Replicas)
+	assert.Equal(t, "ctr", rs.Spec.Template.Labels["paddle-job-heter"])
+	c := rs.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []string{"paddle_k8s", "start_heter"}, c.Command)
+
+	env := map[string]corev1.EnvVar{}
+	for _, e := range c.Env {
+		env[e.Name] = e
+	}
+	assert.Equal(t, "HETER_TRAINER", env["TRAINING_ROLE"].Value)
+	assert.Equal(t, "heter_endpoints", env["PADDLE_HETER_TRAINER_IP_PORT_LIST"].ValueFrom.ConfigMapKeyRef.Key)
+	assert.Equal(t, "ctr-endpoints", env["PADDLE_PSERVERS_IP_PORT_LIST"].ValueFrom.ConfigMapKeyRef.Name)
+
+	for _, e := range p.parseToTrainer(job).Spec.Template.Spec.Containers[0].Env {
+		if e.Name == "TRAINING_ROLE" {
+			assert.Equal(t, "TRAINER", e.Value)
+		}
+	}
+
+	job.Spec.Heter.Replicas = 0
+	assert.Error(t, validate(job))
+}
@@ -1,18 +1,103 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
 package updater
 
 import (
-	"fmt"
-	"strings"
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// The labels the operator sets on every object it creates for a PaddleJob,
+// besides the labels of the PaddleJob itself.
+const (
+	// JobNameLabel is the name of the PaddleJob of an object.
+	JobNameLabel = "paddlepaddle.org/job-name"
+	// JobUIDLabel is the UID of the PaddleJob of an object.
+	JobUIDLabel = "paddlepaddle.org/job-uid"
+	// RoleLabel is the role of the pods of an object: pserver, trainer,
+	// heter, evaluator, master or checkpoint-gc. The objects shared by
+	// the whole job have no role.
+	RoleLabel = "paddlepaddle.org/role"
+	// ManagedByLabel marks the objects managed by the operator.
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// ManagedBy is the value of ManagedByLabel.
+	ManagedBy = "paddle-operator"
+)
+
+// Roles of the pods which are not a TrainingResourceType.
+const (
+	masterRole       = "master"
+	checkpointGCRole = "checkpoint-gc"
 )
 
-// Labels represents labels of k8s resources
-type Labels map[string]string
+// standardLabels returns the labels the operator sets on the objects of
+// job running role, or shared by the whole job if role is empty.
+func standardLabels(job *padv1.PaddleJob, role string) labels.Set {
+	l := labels.Set{
+		JobNameLabel:   job.Name,
+		ManagedByLabel: ManagedBy,
+	}
+	if job.UID != "" {
+		l[JobUIDLabel] = string(job.UID)
+	}
+	if role != "" {
+		l[RoleLabel] = role
+	}
+	return l
+}
 
-// LabelsParser parse labels to selector
-func (l Labels) LabelsParser() (string, error) {
-	pieces := make([]string, 0, len(l))
-	for k, v := range l {
-		pieces = append(pieces, fmt.Sprintf("%v=%v", k, v))
+// objectLabels returns the labels of the objects of job running role: the
+// labels of the PaddleJob, overridden by the standard ones.
+func objectLabels(job *padv1.PaddleJob, role string) labels.Set {
+	l := labels.Merge(job.Labels, standardLabels(job, role))
+	if role == "" {
+		// The role of the shared objects is not taken from the PaddleJob.
+		delete(l, RoleLabel)
 	}
-	return strings.Join(pieces, ","), nil
+	return l
+}
+
+// selectorLabels returns the standard labels the pods of job running role
+// are selected by.
+func selectorLabels(job *padv1.PaddleJob, role string) labels.Set {
+	return labels.Set{JobNameLabel: job.Name, RoleLabel: role}
+}
+
+// roleLabels returns the labels the pods of the resource tp of job are
+// selected by.
+func roleLabels(job *padv1.PaddleJob, tp padv1.TrainingResourceType) labels.Set {
+	return selectorLabels(job, roleContainerName(tp))
+}
+
+// legacyLabels returns the labels the pods of the resource tp of job had
+// before the standard labels. They are still set for the tools reading
+// them, but nothing is selected by them.
+func legacyLabels(job *padv1.PaddleJob, tp padv1.TrainingResourceType) labels.Set {
+	switch tp {
+	case padv1.Pserver:
+		return labels.Set{"paddle-job-pserver": job.Name}
+	case padv1.Heter:
+		return labels.Set{"paddle-job-heter": job.Name}
+	case padv1.Evaluator:
+		return labels.Set{"paddle-job-evaluator": job.Name}
+	}
+	return labels.Set{"paddle-job": job.Name}
+}
+
+// roleSelector selects the pods of the resource tp of job.
+func roleSelector(job *padv1.PaddleJob, tp padv1.TrainingResourceType) labels.Selector {
+	return labels.SelectorFromSet(roleLabels(job, tp))
 }
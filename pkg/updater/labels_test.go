@@ -0,0 +1,63 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+func TestObjectLabels(t *testing.T) {
+	job := &padv1.PaddleJob{}
+	job.Name = "mnist"
+	job.UID = "1"
+	job.Labels = map[string]string{"team": "nlp", "tier": "batch", RoleLabel: "user"}
+	SetDefaults(job, nil)
+	job.Spec.Pserver.Template = &corev1.PodTemplateSpec{}
+	job.Spec.Pserver.Template.Labels = map[string]string{"tier": "online"}
+	p := &DefaultJobParser{}
+
+	rs := p.parseToPserver(job)
+	assert.Equal(t, "pserver", rs.Labels[RoleLabel])
+	assert.Equal(t, "1", rs.Labels[JobUIDLabel])
+	assert.Equal(t, ManagedBy, rs.Labels[ManagedByLabel])
+	assert.Equal(t, "nlp", rs.Labels["team"])
+
+	template := rs.Spec.Template.Labels
+	assert.Equal(t, "mnist", template[JobNameLabel])
+	assert.Equal(t, "pserver", template[RoleLabel])
+	assert.Equal(t, "nlp", template["team"])
+	assert.Equal(t, "online", template["tier"])
+	assert.Equal(t, "mnist", template["paddle-job-pserver"])
+	selector, err := metav1.LabelSelectorAsSelector(rs.Spec.Selector)
+	assert.NoError(t, err)
+	assert.True(t, selector.Matches(labels.Set(template)))
+	assert.Equal(t, roleSelector(job, padv1.Pserver).String(), selector.String())
+	_, ok := rs.Spec.Selector.MatchLabels["paddle-job-pserver"]
+	assert.False(t, ok)
+
+	trainer := p.parseToTrainer(job)
+	assert.Equal(t, "trainer", trainer.Labels[RoleLabel])
+	assert.True(t, roleSelector(job, padv1.Trainer).Matches(labels.Set(trainer.Spec.Template.Labels)))
+	assert.Equal(t, "pserver", roleService(job, padv1.Pserver).Labels[RoleLabel])
+	_, ok = jobNetworkPolicy(job, nil).Labels[RoleLabel]
+	assert.False(t, ok)
+}
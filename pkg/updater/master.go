@@ -0,0 +1,181 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	log "github.com/golang/glog"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	masterPort                = 8080
+	defaultTaskTimeoutSeconds = 600
+	defaultMaxTaskFailures    = 3
+)
+
+// masterName returns the name of the replicaset and the service of the
+// master of job.
+func masterName(job *padv1.PaddleJob) string {
+	return job.Name + "-master"
+}
+
+// masterLabels returns the labels the master pod of job is selected by.
+func masterLabels(job *padv1.PaddleJob) labels.Set {
+	return selectorLabels(job, masterRole)
+}
+
+// masterEndpoint returns the URL the trainers of job reach the master at.
+func masterEndpoint(job *padv1.PaddleJob) string {
+	return fmt.Sprintf("http://%v:%d", masterName(job), masterPort)
+}
+
+// validateMaster validates the master spec of a job.
+func validateMaster(m *padv1.MasterSpec) error {
+	if m != nil && m.Dataset == "" {
+		return fmt.Errorf("master needs the dataset manifest")
+	}
+	return nil
+}
+
+// masterReplicaSet returns the replicaset running the master of job from
+// image. The master reads the dataset from the volumes of the job and
+// persists its queue in the checkpoint directory if there is one.
+func masterReplicaSet(job *padv1.PaddleJob, image string) *v1beta1.ReplicaSet {
+	m := job.Spec.Master
+	if m.Image != "" {
+		image = m.Image
+	}
+	timeout := m.TaskTimeoutSeconds
+	if timeout == 0 {
+		timeout = defaultTaskTimeoutSeconds
+	}
+	maxFailures := m.MaxTaskFailures
+	if maxFailures == 0 {
+		maxFailures = defaultMaxTaskFailures
+	}
+	statePath := m.StatePath
+	if statePath == "" && job.Spec.Checkpoint != nil {
+		statePath = path.Join(checkpointMountPath, "master.json")
+	}
+
+	template := podTemplate(job, masterRole, nil)
+	template.Labels["paddle-job-master"] = job.Name
+	template.Spec.HostNetwork = false
+	template.Spec.Containers = []corev1.Container{
+		{
+			Name:  "master",
+			Image: image,
+			Command: []string{
+				"paddlejob", "--master", "--alsologtostderr",
+				fmt.Sprintf("--master-addr=:%d", masterPort),
+				"--master-dataset=" + m.Dataset,
+				fmt.Sprintf("--master-passes=%d", job.Spec.Passes),
+				fmt.Sprintf("--master-chunks-per-task=%d", m.ChunksPerTask),
+				fmt.Sprintf("--master-task-timeout=%v", time.Duration(timeout)*time.Second),
+				fmt.Sprintf("--master-max-task-failures=%d", maxFailures),
+				"--master-state-path=" + statePath,
+			},
+			Ports:        []corev1.ContainerPort{{Name: "master", ContainerPort: masterPort}},
+			Resources:    m.Resources,
+			VolumeMounts: append([]corev1.VolumeMount(nil), job.Spec.VolumeMounts...),
+			ReadinessProbe: &corev1.Probe{
+				Handler: corev1.Handler{
+					HTTPGet: &corev1.HTTPGetAction{Path: "/status", Port: intstr.FromInt(masterPort)},
+				},
+			},
+		},
+	}
+	setCheckpoint(job, &template, &template.Spec.Containers[0])
+
+	replicas := int32(1)
+	return &v1beta1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            masterName(job),
+			Namespace:       job.Namespace,
+			Labels:          objectLabels(job, masterRole),
+			OwnerReferences: []metav1.OwnerReference{ownerReference(job)},
+		},
+		Spec: v1beta1.ReplicaSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: masterLabels(job)},
+			Template: template,
+		},
+	}
+}
+
+// masterService returns the service the trainers of job reach the master
+// through.
+func masterService(job *padv1.PaddleJob) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            masterName(job),
+			Namespace:       job.Namespace,
+			Labels:          objectLabels(job, masterRole),
+			OwnerReferences: []metav1.OwnerReference{ownerReference(job)},
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: masterLabels(job),
+			Ports:    []corev1.ServicePort{{Name: "master", Port: masterPort, TargetPort: intstr.FromInt(masterPort)}},
+		},
+	}
+}
+
+// createMaster creates the master of the job and its service if they do
+// not exist.
+func (updater *PaddleJobUpdater) createMaster() error {
+	job := updater.job
+	image := updater.config.MasterImage
+	if job.Spec.Master.Image == "" && image == "" {
+		return fmt.Errorf("master needs an image, set master.image or --master-image")
+	}
+	log.Infof("Create master namespace=%v name=%v", job.Namespace, masterName(job))
+	if _, err := updater.kubeClient.ExtensionsV1beta1().ReplicaSets(job.Namespace).Create(masterReplicaSet(job, image)); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	if _, err := updater.kubeClient.CoreV1().Services(job.Namespace).Create(masterService(job)); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// releaseMaster deletes the master of the job and its service.
+func (updater *PaddleJobUpdater) releaseMaster() error {
+	job := updater.job
+	if job.Spec.Master == nil {
+		return nil
+	}
+	log.Infof("Release master namespace=%v name=%v", job.Namespace, masterName(job))
+	propagation := metav1.DeletePropagationBackground
+	err := updater.kubeClient.ExtensionsV1beta1().ReplicaSets(job.Namespace).Delete(masterName(job), &metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err := updater.kubeClient.CoreV1().Services(job.Namespace).Delete(masterName(job), &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
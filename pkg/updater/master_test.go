@@ -0,0 +1,55 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+func TestParseMaster(t *testing.T) {
+	job := &padv1.PaddleJob{}
+	job.Name = "mnist"
+	job.Spec.Passes = 3
+	job.Spec.Master = &padv1.MasterSpec{Dataset: "/data/manifest"}
+	job.Spec.Checkpoint = &padv1.CheckpointSpec{
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "checkpoints"},
+	}
+	SetDefaults(job, nil)
+	assert.NoError(t, validate(job))
+	p := &DefaultJobParser{}
+
+	env := map[string]string{}
+	for _, e := range p.parseToTrainer(job).Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	assert.Equal(t, "http://mnist-master:8080", env["PADDLE_MASTER_ENDPOINT"])
+
+	rs := masterReplicaSet(job, "paddlejob:latest")
+	c := rs.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "paddlejob:latest", c.Image)
+	assert.Contains(t, c.Command, "--master-passes=3")
+	assert.Contains(t, c.Command, "--master-task-timeout=10m0s")
+	assert.Contains(t, c.Command, "--master-state-path=/checkpoint/master.json")
+	assert.True(t, labels.SelectorFromSet(masterService(job).Spec.Selector).Matches(labels.Set(rs.Spec.Template.Labels)))
+
+	job.Spec.Master.Dataset = ""
+	assert.Error(t, validate(job))
+}
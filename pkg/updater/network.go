@@ -0,0 +1,119 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	log "github.com/golang/glog"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// roleServiceName returns the name of the headless service of the
+// resource tp of job.
+func roleServiceName(job *padv1.PaddleJob, tp padv1.TrainingResourceType) string {
+	return job.Name + "-" + roleContainerName(tp)
+}
+
+// roleService returns the headless service of the resource tp of job. It
+// exposes the ports of the job, the name of the service resolves to the
+// IPs of the pods of the role.
+func roleService(job *padv1.PaddleJob, tp padv1.TrainingResourceType) *corev1.Service {
+	var ports []corev1.ServicePort
+	for _, p := range podPorts(job) {
+		ports = append(ports, corev1.ServicePort{Name: p.Name, Port: p.ContainerPort, TargetPort: intstr.FromInt(int(p.ContainerPort))})
+	}
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            roleServiceName(job, tp),
+			Namespace:       job.Namespace,
+			Labels:          objectLabels(job, roleContainerName(tp)),
+			OwnerReferences: []metav1.OwnerReference{ownerReference(job)},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  roleLabels(job, tp),
+			Ports:     ports,
+		},
+	}
+}
+
+// jobNetworkPolicy returns the network policy of job which only lets the
+// pods of the job, and the pods of the namespaces matching operator if it
+// is not empty, connect to the pods of the job.
+func jobNetworkPolicy(job *padv1.PaddleJob, operator map[string]string) *networkingv1.NetworkPolicy {
+	selector := metav1.LabelSelector{MatchLabels: map[string]string{JobNameLabel: job.Name}}
+	peers := []networkingv1.NetworkPolicyPeer{{PodSelector: &selector}}
+	if len(operator) > 0 {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{NamespaceSelector: &metav1.LabelSelector{MatchLabels: operator}})
+	}
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            job.Name,
+			Namespace:       job.Namespace,
+			Labels:          objectLabels(job, ""),
+			OwnerReferences: []metav1.OwnerReference{ownerReference(job)},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: selector,
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{{From: peers}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// createNetwork creates the headless services of the roles of the job and
+// its network policy if it asks for one.
+func (updater *PaddleJobUpdater) createNetwork() error {
+	job := updater.job
+	for _, tp := range jobRoles(job) {
+		log.Infof("Create service namespace=%v name=%v", job.Namespace, roleServiceName(job, tp))
+		if _, err := updater.kubeClient.CoreV1().Services(job.Namespace).Create(roleService(job, tp)); err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	if !job.Spec.NetworkPolicy {
+		return nil
+	}
+	log.Infof("Create network policy namespace=%v name=%v", job.Namespace, job.Name)
+	_, err := updater.kubeClient.NetworkingV1().NetworkPolicies(job.Namespace).Create(jobNetworkPolicy(job, updater.config.OperatorNamespaceLabels))
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// deleteNetwork deletes the headless services and the network policy of
+// the job.
+func (updater *PaddleJobUpdater) deleteNetwork() error {
+	job := updater.job
+	for _, tp := range jobRoles(job) {
+		if err := updater.kubeClient.CoreV1().Services(job.Namespace).Delete(roleServiceName(job, tp), &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	if !job.Spec.NetworkPolicy {
+		return nil
+	}
+	if err := updater.kubeClient.NetworkingV1().NetworkPolicies(job.Namespace).Delete(job.Name, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+func TestJobNetwork(t *testing.T) {
+	job := &padv1.PaddleJob{}
+	job.Name = "mnist"
+	SetDefaults(job, nil)
+	p := &DefaultJobParser{}
+	pserver := p.parseToPserver(job).Spec.Template
+	trainer := p.parseToTrainer(job).Spec.Template
+
+	svc := roleService(job, padv1.Pserver)
+	assert.Equal(t, "mnist-pserver", svc.Name)
+	assert.Equal(t, corev1.ClusterIPNone, svc.Spec.ClusterIP)
+	assert.Len(t, svc.Spec.Ports, job.Spec.PortsNum+job.Spec.PortsNumForSparse)
+	assert.True(t, labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(pserver.Labels)))
+	assert.False(t, labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(trainer.Labels)))
+
+	policy := jobNetworkPolicy(job, map[string]string{"name": "paddle-operator"})
+	selector := labels.SelectorFromSet(policy.Spec.PodSelector.MatchLabels)
+	assert.True(t, selector.Matches(labels.Set(pserver.Labels)))
+	assert.True(t, selector.Matches(labels.Set(trainer.Labels)))
+	assert.Len(t, policy.Spec.Ingress[0].From, 2)
+}
@@ -0,0 +1,164 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/golang/glog"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// startingReasons are the waiting reasons of containers which are
+// starting normally.
+var startingReasons = map[string]bool{
+	"":                  true,
+	"ContainerCreating": true,
+	"PodInitializing":   true,
+}
+
+// pendingSummary summarizes the pods of a job which are not running yet.
+type pendingSummary struct {
+	pods    int
+	reason  string
+	message string
+	// unschedulable is when the first unschedulable pod became
+	// unschedulable, zero if all the pods are scheduled.
+	unschedulable time.Time
+	// unexplained is a pending pod without a reason.
+	unexplained *corev1.Pod
+}
+
+// podPendingReason returns why a pending pod is not running: the reason
+// it cannot be scheduled or the waiting reason of one of its containers.
+// The reason is empty if the pod is scheduled and starting normally.
+func podPendingReason(pod *corev1.Pod) (reason, message string, unschedulable time.Time) {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse {
+			if c.Reason == corev1.PodReasonUnschedulable {
+				unschedulable = c.LastTransitionTime.Time
+			}
+			return c.Reason, c.Message, unschedulable
+		}
+	}
+	var statuses []corev1.ContainerStatus
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if w := cs.State.Waiting; w != nil && !startingReasons[w.Reason] {
+			return w.Reason, w.Message, unschedulable
+		}
+	}
+	return "", "", unschedulable
+}
+
+// summarizePending summarizes the pending pods among pods. The reason of
+// the summary is the most common reason of the pending pods.
+func summarizePending(pods []*corev1.Pod) pendingSummary {
+	var s pendingSummary
+	counts := make(map[string]int)
+	for _, pod := range rankPods(pods) {
+		if pod.Status.Phase != corev1.PodPending || pod.DeletionTimestamp != nil {
+			continue
+		}
+		s.pods++
+		reason, message, unschedulable := podPendingReason(pod)
+		if !unschedulable.IsZero() && (s.unschedulable.IsZero() || unschedulable.Before(s.unschedulable)) {
+			s.unschedulable = unschedulable
+		}
+		if reason == "" {
+			if s.unexplained == nil {
+				s.unexplained = pod
+			}
+			continue
+		}
+		counts[reason]++
+		if counts[reason] > counts[s.reason] {
+			s.reason, s.message = reason, message
+		}
+	}
+	return s
+}
+
+// pendingReason formats the reason of the status of a job with pending
+// pods.
+func pendingReason(s pendingSummary) string {
+	if s.reason == "" {
+		return ""
+	}
+	if s.message == "" {
+		return fmt.Sprintf("%d pods pending: %v", s.pods, s.reason)
+	}
+	return fmt.Sprintf("%d pods pending: %v: %v", s.pods, s.reason, s.message)
+}
+
+// updatePending reports the pending pods of the job in status. It fails the
+// job if a pod has been unschedulable for longer than the unschedulable
+// timeout.
+func (updater *PaddleJobUpdater) updatePending(status *padv1.PaddleJobStatus) {
+	var pods []*corev1.Pod
+	for _, tp := range jobRoles(updater.job) {
+		rp, err := updater.rolePods(tp)
+		if err != nil {
+			log.Errorf("list pods of %v error: %v", tp, err)
+			return
+		}
+		pods = append(pods, rp...)
+	}
+
+	s := summarizePending(pods)
+	if s.reason == "" && s.unexplained != nil {
+		s.reason, s.message = updater.podEventReason(s.unexplained)
+	}
+	status.PendingPods = s.pods
+	status.PendingReason = pendingReason(s)
+
+	timeout := updater.config.UnschedulableTimeout
+	if timeout > 0 && !s.unschedulable.IsZero() && time.Since(s.unschedulable) > timeout {
+		status.Phase = padv1.PaddleJobPhaseFailed
+		status.Reason = fmt.Sprintf("pods are unschedulable for more than %v; %v", timeout, status.PendingReason)
+	}
+}
+
+// podEventReason returns the reason and the message of the last warning
+// event of a pod, e.g. a volume which cannot be mounted.
+func (updater *PaddleJobUpdater) podEventReason(pod *corev1.Pod) (string, string) {
+	selector := fields.Set{
+		"involvedObject.kind": "Pod",
+		"involvedObject.name": pod.Name,
+		"type":                corev1.EventTypeWarning,
+	}.AsSelector().String()
+	events, err := updater.kubeClient.CoreV1().Events(pod.Namespace).List(metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		log.Warningf("list events of pod namespace=%v name=%v error: %v", pod.Namespace, pod.Name, err)
+		return "", ""
+	}
+	var last *corev1.Event
+	for i := range events.Items {
+		if e := &events.Items[i]; last == nil || last.LastTimestamp.Time.Before(e.LastTimestamp.Time) {
+			last = e
+		}
+	}
+	if last == nil {
+		return "", ""
+	}
+	return last.Reason, last.Message
+}
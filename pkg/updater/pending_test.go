@@ -0,0 +1,62 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSummarizePending(t *testing.T) {
+	unschedulable := func(name string, since int64) *corev1.Pod {
+		pod := testPod(name, 1, corev1.PodPending, false)
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:               corev1.PodScheduled,
+			Status:             corev1.ConditionFalse,
+			Reason:             corev1.PodReasonUnschedulable,
+			Message:            "0/3 nodes are available: 3 Insufficient nvidia.com/gpu.",
+			LastTransitionTime: metav1.Unix(since, 0),
+		}}
+		return pod
+	}
+	pullError := testPod("pull", 2, corev1.PodPending, false)
+	pullError.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "trainer",
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+	}}
+	creating := testPod("creating", 3, corev1.PodPending, false)
+	creating.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "trainer",
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+	}}
+	running := testPod("running", 4, corev1.PodRunning, true)
+
+	s := summarizePending([]*corev1.Pod{unschedulable("a", 20), unschedulable("b", 10), pullError, creating, running})
+	assert.Equal(t, 4, s.pods)
+	assert.Equal(t, "4 pods pending: Unschedulable: 0/3 nodes are available: 3 Insufficient nvidia.com/gpu.", pendingReason(s))
+	assert.Equal(t, metav1.Unix(10, 0).Time, s.unschedulable)
+	assert.Equal(t, "creating", s.unexplained.Name)
+
+	s = summarizePending([]*corev1.Pod{pullError, running})
+	assert.Equal(t, "1 pods pending: ImagePullBackOff", pendingReason(s))
+	assert.True(t, s.unschedulable.IsZero())
+
+	s = summarizePending([]*corev1.Pod{running})
+	assert.Equal(t, 0, s.pods)
+	assert.Equal(t, "", pendingReason(s))
+}
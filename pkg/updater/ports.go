@@ -0,0 +1,226 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	log "github.com/golang/glog"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// allocateRetries is the number of times an allocation is retried when
+// the ConfigMap was changed concurrently.
+const allocateRetries = 5
+
+// portRangeAllocation is a range of host ports allocated to a job.
+type portRangeAllocation struct {
+	first, last int
+}
+
+// PortAllocator allocates the host ports of the jobs using the host
+// network. Every job gets a range of ports no other job uses, so the
+// pservers and the trainers of two jobs never collide on a node, and the
+// pods declare them as host ports so the scheduler keeps them away from the
+// nodes where other pods use them. The allocations are persisted in a
+// ConfigMap, one key per job.
+type PortAllocator struct {
+	mu        sync.Mutex
+	client    kubernetes.Interface
+	namespace string
+	name      string
+	first     int
+	last      int
+}
+
+// NewPortAllocator creates an allocator of the host ports first to last
+// persisting its allocations in the ConfigMap namespace/name.
+func NewPortAllocator(client kubernetes.Interface, namespace, name string, first, last int) *PortAllocator {
+	return &PortAllocator{client: client, namespace: namespace, name: name, first: first, last: last}
+}
+
+// portsKey returns the key of the allocation of job.
+func portsKey(job *padv1.PaddleJob) string {
+	return job.Namespace + "." + job.Name
+}
+
+// portsNum returns the number of host ports of job.
+func portsNum(job *padv1.PaddleJob) int {
+	if n := job.Spec.PortsNum + job.Spec.PortsNumForSparse; n > 0 {
+		return n
+	}
+	return 1
+}
+
+// parsePortRange parses a range like "7164-7167".
+func parsePortRange(s string) (portRangeAllocation, error) {
+	r := portRangeAllocation{}
+	if _, err := fmt.Sscanf(s, "%d-%d", &r.first, &r.last); err != nil {
+		return r, fmt.Errorf("invalid port range %q: %v", s, err)
+	}
+	if r.first <= 0 || r.last > 65535 || r.first > r.last {
+		return r, fmt.Errorf("invalid port range %q", s)
+	}
+	return r, nil
+}
+
+// firstFit returns the first port from first of n free ports up to last,
+// used are the ranges already allocated.
+func firstFit(used []portRangeAllocation, first, last, n int) (int, bool) {
+	sort.Slice(used, func(i, j int) bool { return used[i].first < used[j].first })
+	base := first
+	for _, r := range used {
+		if r.first-base >= n {
+			break
+		}
+		if r.last >= base {
+			base = r.last + 1
+		}
+	}
+	return base, base+n-1 <= last
+}
+
+// allocate returns the first port of the range allocated to key in data,
+// allocating n ports if key has none.
+func allocate(data map[string]string, key string, first, last, n int) (int, bool, error) {
+	if s, ok := data[key]; ok {
+		r, err := parsePortRange(s)
+		if err == nil && r.last-r.first+1 == n {
+			return r.first, false, nil
+		}
+	}
+	var used []portRangeAllocation
+	for k, s := range data {
+		if k == key {
+			continue
+		}
+		r, err := parsePortRange(s)
+		if err != nil {
+			log.Warningf("skip host port allocation %v: %v", k, err)
+			continue
+		}
+		used = append(used, r)
+	}
+	base, ok := firstFit(used, first, last, n)
+	if !ok {
+		return 0, false, fmt.Errorf("no %d free host ports in %d-%d", n, first, last)
+	}
+	data[key] = fmt.Sprintf("%d-%d", base, base+n-1)
+	return base, true, nil
+}
+
+// Allocate returns the first of the host ports allocated to job, they are
+// allocated if the job has none yet.
+func (a *PortAllocator) Allocate(job *padv1.PaddleJob) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var err error
+	for i := 0; i < allocateRetries; i++ {
+		var cm *corev1.ConfigMap
+		cm, err = a.configMap()
+		if err != nil {
+			return 0, err
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		base, changed, allocErr := allocate(cm.Data, portsKey(job), a.first, a.last, portsNum(job))
+		if allocErr != nil || !changed {
+			return base, allocErr
+		}
+		if _, err = a.client.CoreV1().ConfigMaps(a.namespace).Update(cm); err == nil {
+			log.Infof("Allocate host ports %v to PaddleJob namespace=%v name=%v", cm.Data[portsKey(job)], job.Namespace, job.Name)
+			return base, nil
+		}
+		if !errors.IsConflict(err) {
+			return 0, err
+		}
+	}
+	return 0, err
+}
+
+// Release releases the host ports allocated to job.
+func (a *PortAllocator) Release(job *padv1.PaddleJob) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var err error
+	for i := 0; i < allocateRetries; i++ {
+		var cm *corev1.ConfigMap
+		cm, err = a.configMap()
+		if err != nil {
+			return err
+		}
+		if _, ok := cm.Data[portsKey(job)]; !ok {
+			return nil
+		}
+		log.Infof("Release host ports %v of PaddleJob namespace=%v name=%v", cm.Data[portsKey(job)], job.Namespace, job.Name)
+		delete(cm.Data, portsKey(job))
+		if _, err = a.client.CoreV1().ConfigMaps(a.namespace).Update(cm); err == nil || !errors.IsConflict(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// configMap returns the ConfigMap of the allocations, creating it if it
+// does not exist.
+func (a *PortAllocator) configMap() (*corev1.ConfigMap, error) {
+	configMaps := a.client.CoreV1().ConfigMaps(a.namespace)
+	cm, err := configMaps.Get(a.name, metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		return cm, err
+	}
+	cm, err = configMaps.Create(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: a.name, Namespace: a.namespace}})
+	if errors.IsAlreadyExists(err) {
+		return configMaps.Get(a.name, metav1.GetOptions{})
+	}
+	return cm, err
+}
+
+// allocateHostPorts sets the first port of a job using the host network
+// to the host ports allocated to it.
+func (updater *PaddleJobUpdater) allocateHostPorts() error {
+	job := updater.job
+	if !job.Spec.HostNetwork || updater.config.HostPorts == nil {
+		return nil
+	}
+	// The number of ports is known once the job is defaulted.
+	defaulted := job.DeepCopy()
+	SetDefaults(defaulted, &updater.config.Defaults)
+	base, err := updater.config.HostPorts.Allocate(defaulted)
+	if err != nil {
+		return err
+	}
+	job.Spec.Port = base
+	return nil
+}
+
+// releaseHostPorts releases the host ports allocated to the job.
+func (updater *PaddleJobUpdater) releaseHostPorts() error {
+	if !updater.job.Spec.HostNetwork || updater.config.HostPorts == nil {
+		return nil
+	}
+	return updater.config.HostPorts.Release(updater.job)
+}
@@ -0,0 +1,50 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+func TestAllocateHostPorts(t *testing.T) {
+	data := map[string]string{"ns.a": "20000-20001", "ns.b": "20004-20005"}
+	base, changed, err := allocate(data, "ns.c", 20000, 20009, 2)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, 20002, base)
+	assert.Equal(t, "20002-20003", data["ns.c"])
+
+	// An allocated job keeps its ports.
+	base, changed, err = allocate(data, "ns.c", 20000, 20009, 2)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 20002, base)
+
+	base, _, err = allocate(data, "ns.d", 20000, 20009, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 20006, base)
+	_, _, err = allocate(data, "ns.e", 20000, 20009, 2)
+	assert.Error(t, err)
+
+	job := &padv1.PaddleJob{}
+	job.Spec.HostNetwork = true
+	job.Spec.Port, job.Spec.PortsNum, job.Spec.PortsNumForSparse = 20002, 1, 1
+	ports := podPorts(job)
+	assert.Equal(t, int32(20003), ports[1].HostPort)
+}
@@ -0,0 +1,214 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	log "github.com/golang/glog"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProgressAnnotation is the annotation of its own pod a trainer reports its
+// progress in, e.g. {"pass": 2, "batch": 300, "batches": 1000,
+// "metrics": {"loss": 0.42}}.
+const ProgressAnnotation = "paddlepaddle.org/progress"
+
+// progressReport is the progress reported by a trainer.
+type progressReport struct {
+	// Pass is the current pass, starting from 0.
+	Pass int `json:"pass"`
+	// Batch is the number of batches done in the current pass.
+	Batch int `json:"batch"`
+	// Batches is the number of batches of a pass, if known.
+	Batches int                `json:"batches,omitempty"`
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+}
+
+// podProgress returns the progress reported by a trainer pod, or nil if
+// it has not reported any.
+func podProgress(pod *corev1.Pod) (*progressReport, error) {
+	value, ok := pod.Annotations[ProgressAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	r := &progressReport{}
+	if err := json.Unmarshal([]byte(value), r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// before returns true if r is behind other.
+func (r *progressReport) before(other *progressReport) bool {
+	if r.Pass != other.Pass {
+		return r.Pass < other.Pass
+	}
+	return r.Batch < other.Batch
+}
+
+// done returns the part of passes done.
+func (r *progressReport) done(passes int) float64 {
+	if passes <= 0 {
+		return 0
+	}
+	done := float64(r.Pass)
+	if r.Batches > 0 && r.Batch < r.Batches {
+		done += float64(r.Batch) / float64(r.Batches)
+	}
+	if done > float64(passes) {
+		return 1
+	}
+	return done / float64(passes)
+}
+
+// jobProgress returns the progress of the slowest running trainer among
+// pods, previous is the last progress of the job and passes its number of
+// passes. The progress is updated at now when it changes.
+func jobProgress(previous *padv1.TrainingProgress, pods []*corev1.Pod, passes int, now time.Time) *padv1.TrainingProgress {
+	var slowest *progressReport
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		r, err := podProgress(pod)
+		if err != nil {
+			log.Warningf("invalid progress of pod namespace=%v name=%v: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		if r != nil && (slowest == nil || r.before(slowest)) {
+			slowest = r
+		}
+	}
+	if slowest == nil {
+		return previous
+	}
+
+	p := previous.DeepCopy()
+	if p == nil {
+		p = &padv1.TrainingProgress{StartTime: metav1.NewTime(now), LastUpdateTime: metav1.NewTime(now)}
+	} else if p.Pass != slowest.Pass || p.Batch != slowest.Batch {
+		p.LastUpdateTime = metav1.NewTime(now)
+	}
+	p.Pass, p.Batch = slowest.Pass, slowest.Batch
+	p.Metrics = nil
+	for name, value := range slowest.Metrics {
+		if p.Metrics == nil {
+			p.Metrics = make(map[string]string, len(slowest.Metrics))
+		}
+		p.Metrics[name] = strconv.FormatFloat(value, 'g', 6, 64)
+	}
+
+	done := slowest.done(passes)
+	p.Percent = int(done * 100)
+	p.EstimatedCompletionTime = nil
+	if elapsed := p.LastUpdateTime.Sub(p.StartTime.Time); done > 0 && done < 1 && elapsed > 0 {
+		remaining := time.Duration(float64(elapsed) * (1 - done) / done)
+		eta := metav1.NewTime(p.LastUpdateTime.Add(remaining))
+		p.EstimatedCompletionTime = &eta
+	}
+	return p
+}
+
+// trainerProgress returns the progress of the trainers of the job,
+// previous is the last reported progress.
+func (updater *PaddleJobUpdater) trainerProgress(previous *padv1.TrainingProgress) *padv1.TrainingProgress {
+	pods, err := updater.rolePods(padv1.Trainer)
+	if err != nil {
+		log.Errorf("list trainers of namespace=%v name=%v error: %v", updater.job.Namespace, updater.job.Name, err)
+		return previous
+	}
+	return jobProgress(previous, pods, updater.job.Spec.Passes, time.Now())
+}
+
+// checkStall applies the stall policy of the job if its trainers have not
+// made progress for longer than its stall timeout. It returns true if the
+// job is being restarted.
+func (updater *PaddleJobUpdater) checkStall() bool {
+	timeout := updater.job.Spec.StallTimeout
+	progress := updater.status.Progress
+	if timeout == nil || timeout.Duration <= 0 || progress == nil || updater.status.Phase != padv1.PaddleJobPhaseRunning {
+		return false
+	}
+	last := progress.LastUpdateTime.Time
+	if time.Since(last) < timeout.Duration || updater.stalled.Equal(last) {
+		return false
+	}
+	// A stall is handled once, until the trainers make progress again.
+	updater.stalled = last
+
+	policy := updater.job.Spec.StallPolicy
+	if policy == "" {
+		policy = padv1.StallPolicyWarn
+	}
+	if policy == padv1.StallPolicyRestartJob && updater.status.Restarts >= updater.maxRestarts() {
+		policy = padv1.StallPolicyFail
+	}
+	reason := fmt.Sprintf("trainers made no progress for %v since pass %d batch %d", timeout.Duration, progress.Pass, progress.Batch)
+	log.Warningf("PaddleJob namespace=%v name=%v: %v, apply policy %v", updater.job.Namespace, updater.job.Name, reason, policy)
+	updater.recordEvent(corev1.EventTypeWarning, "Stalled", reason)
+
+	switch policy {
+	case padv1.StallPolicyFail:
+		updater.status.Phase = padv1.PaddleJobPhaseFailed
+		updater.status.Reason = reason
+	case padv1.StallPolicyRestartJob:
+		if err := updater.restartJob(); err != nil {
+			updater.status.Phase = padv1.PaddleJobPhaseFailed
+			updater.status.Reason = "Internal error; restart job error:" + err.Error()
+			return false
+		}
+		// The restarted trainers have a new stall timeout to report.
+		progress.LastUpdateTime = metav1.Now()
+		return true
+	}
+	return false
+}
+
+// recordEvent records an event of the job.
+func (updater *PaddleJobUpdater) recordEvent(eventType, reason, message string) {
+	job := updater.job
+	now := metav1.Now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%v.%x", job.Name, now.UnixNano()),
+			Namespace: job.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: padv1.SchemeGroupVersion.String(),
+			Kind:       padv1.CRDKind,
+			Name:       job.Name,
+			Namespace:  job.Namespace,
+			UID:        job.UID,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Source:         corev1.EventSource{Component: "paddle-operator"},
+	}
+	if _, err := updater.kubeClient.CoreV1().Events(job.Namespace).Create(event); err != nil {
+		log.Warningf("record event of PaddleJob namespace=%v name=%v error: %v", job.Namespace, job.Name, err)
+	}
+}
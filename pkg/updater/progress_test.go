@@ -0,0 +1,62 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestJobProgress(t *testing.T) {
+	trainer := func(name, progress string) *corev1.Pod {
+		pod := testPod(name, 1, corev1.PodRunning, true)
+		if progress != "" {
+			pod.Annotations = map[string]string{ProgressAnnotation: progress}
+		}
+		return pod
+	}
+	start := time.Unix(1000, 0)
+
+	assert.Nil(t, jobProgress(nil, []*corev1.Pod{trainer("a", "")}, 4, start))
+
+	pods := []*corev1.Pod{
+		trainer("a", `{"pass": 1, "batch": 50, "batches": 100, "metrics": {"loss": 0.5}}`),
+		trainer("b", `{"pass": 1, "batch": 20, "batches": 100, "metrics": {"loss": 0.25}}`),
+		trainer("c", `not json`),
+	}
+	p := jobProgress(nil, pods, 4, start)
+	assert.Equal(t, 1, p.Pass)
+	assert.Equal(t, 20, p.Batch)
+	assert.Equal(t, 30, p.Percent)
+	assert.Equal(t, map[string]string{"loss": "0.25"}, p.Metrics)
+	assert.Equal(t, start, p.StartTime.Time)
+	assert.Nil(t, p.EstimatedCompletionTime)
+
+	// The same progress does not move the last update time.
+	same := jobProgress(p, pods, 4, start.Add(time.Minute))
+	assert.Equal(t, start, same.LastUpdateTime.Time)
+
+	pods[1] = trainer("b", `{"pass": 2, "batch": 0, "batches": 100}`)
+	p = jobProgress(p, pods, 4, start.Add(time.Minute))
+	assert.Equal(t, 1, p.Pass)
+	assert.Equal(t, 50, p.Batch)
+	assert.Equal(t, 37, p.Percent)
+	assert.Equal(t, start.Add(time.Minute), p.LastUpdateTime.Time)
+	// 3/8 of the job is done in a minute, 5/8 remain.
+	assert.Equal(t, start.Add(time.Minute+100*time.Second), p.EstimatedCompletionTime.Time)
+}
@@ -0,0 +1,206 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/golang/glog"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// maxPserverFailures bounds the pserver failures kept in the status.
+	maxPserverFailures = 10
+	// defaultMaxRestarts is the number of restarts of the RestartJob
+	// policy if the job does not set it.
+	defaultMaxRestarts = 3
+)
+
+// pserverFailure is a failure of a pserver pod detected by pserverFailures.
+type pserverFailure struct {
+	pod    string
+	reason string
+}
+
+// pserverFailures compares the pserver pods with the restart counts of the
+// pservers seen running at the last check. It returns the restart counts of
+// the running pservers and the failures of the pservers which restarted,
+// failed or disappeared since then. last is nil at the first check.
+func pserverFailures(last map[string]int32, pods []*corev1.Pod) (map[string]int32, []pserverFailure) {
+	current := make(map[string]int32, len(pods))
+	var failures []pserverFailure
+	for _, pod := range pods {
+		_, known := last[pod.Name]
+		cs := roleContainerStatus(pod, roleContainerName(padv1.Pserver))
+		if pod.Status.Phase == corev1.PodFailed || pod.DeletionTimestamp != nil {
+			if known {
+				reason := "failed"
+				if pod.Status.Reason != "" {
+					reason += ": " + pod.Status.Reason
+				}
+				failures = append(failures, pserverFailure{pod: pod.Name, reason: reason})
+			}
+			continue
+		}
+		restarts := int32(0)
+		if cs != nil {
+			restarts = cs.RestartCount
+		}
+		current[pod.Name] = restarts
+		if known && restarts > last[pod.Name] {
+			reason := "restarted"
+			if t := lastTermination(cs); t != nil {
+				reason = fmt.Sprintf("restarted: %v, exit code %d", t.Reason, t.ExitCode)
+			}
+			failures = append(failures, pserverFailure{pod: pod.Name, reason: reason})
+		}
+	}
+	for name := range last {
+		if _, ok := current[name]; ok {
+			continue
+		}
+		lost := true
+		for _, f := range failures {
+			if f.pod == name {
+				lost = false
+			}
+		}
+		if lost {
+			failures = append(failures, pserverFailure{pod: name, reason: "lost"})
+		}
+	}
+	return current, failures
+}
+
+// checkPservers detects the failures of the running pservers of the job and
+// applies the failure policy of the job. It returns true if the job is being
+// restarted.
+func (updater *PaddleJobUpdater) checkPservers() bool {
+	pods, err := updater.rolePods(padv1.Pserver)
+	if err != nil {
+		log.Errorf("list pservers of namespace=%v name=%v error: %v", updater.job.Namespace, updater.job.Name, err)
+		return false
+	}
+	var failures []pserverFailure
+	updater.pservers, failures = pserverFailures(updater.pservers, pods)
+	if len(failures) == 0 {
+		return false
+	}
+
+	policy := updater.job.Spec.Pserver.FailurePolicy
+	if policy == "" {
+		policy = padv1.PserverFailurePolicyFail
+	}
+	if policy == padv1.PserverFailurePolicyRestartJob && updater.status.Restarts >= updater.maxRestarts() {
+		log.Warningf("PaddleJob namespace=%v name=%v has been restarted %d times", updater.job.Namespace, updater.job.Name, updater.status.Restarts)
+		policy = padv1.PserverFailurePolicyFail
+	}
+
+	for _, f := range failures {
+		log.Warningf("pserver pod namespace=%v name=%v %v, apply policy %v", updater.job.Namespace, f.pod, f.reason, policy)
+		updater.status.PserverFailures = append(updater.status.PserverFailures, padv1.PserverFailure{
+			Pod:    f.pod,
+			Reason: f.reason,
+			Action: policy,
+			Time:   metav1.Now(),
+		})
+	}
+	if n := len(updater.status.PserverFailures); n > maxPserverFailures {
+		updater.status.PserverFailures = updater.status.PserverFailures[n-maxPserverFailures:]
+	}
+
+	switch policy {
+	case padv1.PserverFailurePolicyFail:
+		updater.status.Phase = padv1.PaddleJobPhaseFailed
+		updater.status.Reason = fmt.Sprintf("pserver pod %v %v", failures[0].pod, failures[0].reason)
+	case padv1.PserverFailurePolicyRestartJob:
+		if err := updater.restartJob(); err != nil {
+			updater.status.Phase = padv1.PaddleJobPhaseFailed
+			updater.status.Reason = "Internal error; restart job error:" + err.Error()
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// maxRestarts returns the number of times the job may be restarted.
+func (updater *PaddleJobUpdater) maxRestarts() int {
+	if n := updater.job.Spec.Pserver.MaxRestarts; n > 0 {
+		return n
+	}
+	return defaultMaxRestarts
+}
+
+// restartJob deletes the trainers and the pservers of the job and moves it
+// back to creating, the trainers resume from their last checkpoint. The new
+// trainer job is created once the pservers are ready again and the previous
+// trainer job is gone.
+func (updater *PaddleJobUpdater) restartJob() error {
+	job := updater.job
+	log.Infof("Restart PaddleJob namespace=%v name=%v", job.Namespace, job.Name)
+
+	trainer := job.Spec.Trainer.ReplicaSpec.Name
+	propagation := metav1.DeletePropagationBackground
+	err := updater.kubeClient.BatchV1().Jobs(job.Namespace).Delete(trainer, &metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err := updater.releaseTrainer(); err != nil {
+		return err
+	}
+
+	if err := updater.resumeFromCheckpoint(); err != nil {
+		return err
+	}
+
+	// The pserver and heter replicasets create fresh pods. The pod cache
+	// may not have seen the deletion yet, the deleted pods are remembered
+	// so they are not taken for ready ones.
+	updater.released = map[string]bool{}
+	for _, tp := range jobRoles(job) {
+		if tp == padv1.Trainer {
+			continue
+		}
+		pods, err := updater.rolePods(tp)
+		if err != nil {
+			return err
+		}
+		selector := roleSelector(job, tp).String()
+		if err := updater.kubeClient.CoreV1().Pods(job.Namespace).DeleteCollection(&metav1.DeleteOptions{},
+			metav1.ListOptions{LabelSelector: selector}); err != nil {
+			return err
+		}
+		for _, pod := range pods {
+			updater.released[pod.Name] = true
+		}
+	}
+
+	updater.pservers = nil
+	updater.creating = time.Time{}
+	updater.status.Restarts++
+	// The states of the previous pods do not apply to the new ones.
+	updater.status.ReplicaStatuses = nil
+	updater.status.Phase = padv1.PaddleJobPhaseCreating
+	updater.status.Reason = ""
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPserverFailures(t *testing.T) {
+	running := func(name string, restarts int32) *corev1.Pod {
+		pod := testPod(name, 1, corev1.PodRunning, true)
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "pserver", RestartCount: restarts}}
+		return pod
+	}
+
+	// The first check records the running pservers.
+	last, failures := pserverFailures(nil, []*corev1.Pod{running("a", 0), running("b", 1)})
+	assert.Empty(t, failures)
+	assert.Equal(t, map[string]int32{"a": 0, "b": 1}, last)
+
+	last, failures = pserverFailures(last, []*corev1.Pod{running("a", 0), running("b", 1)})
+	assert.Empty(t, failures)
+
+	// b restarted, a is replaced by c.
+	last, failures = pserverFailures(last, []*corev1.Pod{running("b", 2), running("c", 0)})
+	assert.Len(t, failures, 2)
+	assert.Equal(t, pserverFailure{pod: "b", reason: "restarted"}, failures[0])
+	assert.Equal(t, pserverFailure{pod: "a", reason: "lost"}, failures[1])
+
+	evicted := running("c", 0)
+	evicted.Status.Phase = corev1.PodFailed
+	evicted.Status.Reason = "Evicted"
+	_, failures = pserverFailures(last, []*corev1.Pod{running("b", 2), evicted})
+	assert.Equal(t, []pserverFailure{{pod: "c", reason: "failed: Evicted"}}, failures)
+}
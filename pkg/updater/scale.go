@@ -0,0 +1,83 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"fmt"
+
+	log "github.com/golang/glog"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// validateScale validates job running n trainers.
+func validateScale(job *padv1.PaddleJob, n int) error {
+	t := job.Spec.Trainer
+	if n < 1 {
+		return fmt.Errorf("trainer needs at least one instance")
+	}
+	if t.MaxInstance > 0 && n > t.MaxInstance {
+		return fmt.Errorf("%d trainers are more than max-instance %d", n, t.MaxInstance)
+	}
+	scaled := job.DeepCopy()
+	scaled.Spec.Trainer.MinInstance = n
+	return validateSuccessPolicy(scaled)
+}
+
+// scaleTrainers applies the number of trainers of nj, the PaddleJob as
+// modified by hand or through its scale subresource, to the job: the
+// parallelism of the trainer job follows min-instance. The other changes
+// of the spec of a started job are not applied.
+func (updater *PaddleJobUpdater) scaleTrainers(nj *padv1.PaddleJob) error {
+	job := updater.job
+	n := nj.Spec.Trainer.MinInstance
+	if n == job.Spec.Trainer.MinInstance ||
+		updater.status.Phase == padv1.PaddleJobPhaseSucceeded || updater.status.Phase == padv1.PaddleJobPhaseFailed {
+		return nil
+	}
+	if err := validateScale(job, n); err != nil {
+		// The trainers keep running with the previous number.
+		return err
+	}
+	log.Infof("Scale trainers namespace=%v name=%v from %d to %d", job.Namespace, job.Name, job.Spec.Trainer.MinInstance, n)
+	job.Spec.Trainer.MinInstance = n
+	desired := job.Spec.Trainer.ReplicaSpec
+	if desired == nil {
+		// The trainer job is generated with n trainers.
+		return nil
+	}
+	parallelism := int32(n)
+	desired.Spec.Parallelism = &parallelism
+	if updater.status.Phase != padv1.PaddleJobPhaseRunning {
+		// The trainer job is created with the desired parallelism.
+		return nil
+	}
+
+	client := updater.kubeClient.BatchV1().Jobs(job.Namespace)
+	j, err := client.Get(desired.Name, metav1.GetOptions{})
+	if err != nil {
+		// The drift reconciliation recreates or updates it.
+		return err
+	}
+	j.Spec.Parallelism = &parallelism
+	setSpecHash(&j.ObjectMeta, desired.Spec)
+	if j, err = client.Update(j); err != nil {
+		return err
+	}
+	updater.observeGeneration(j.ObjectMeta)
+	return nil
+}
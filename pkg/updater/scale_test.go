@@ -0,0 +1,55 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+func TestScaleTrainers(t *testing.T) {
+	job := &padv1.PaddleJob{}
+	job.Name = "mnist"
+	job.Spec.Trainer.MinInstance = 2
+	job.Spec.Trainer.MaxInstance = 4
+	job, err := (&DefaultJobParser{}).NewPaddleJob(job)
+	assert.NoError(t, err)
+	updater := &PaddleJobUpdater{job: job, config: &Config{}}
+	updater.status.Phase = padv1.PaddleJobPhaseCreating
+
+	scaled := job.DeepCopy()
+	scaled.ResourceVersion = "2"
+	scaled.Spec.Trainer.MinInstance = 3
+	assert.NoError(t, updater.scaleTrainers(scaled))
+	// Only the number of trainers is applied, the job is not replaced by
+	// the modified PaddleJob.
+	assert.Equal(t, "", updater.job.ResourceVersion)
+	assert.Equal(t, 3, updater.job.Spec.Trainer.MinInstance)
+	assert.Equal(t, int32(3), *updater.job.Spec.Trainer.ReplicaSpec.Spec.Parallelism)
+
+	scaled.Spec.Trainer.MinInstance = 5
+	assert.Error(t, updater.scaleTrainers(scaled))
+	scaled.Spec.Trainer.MinInstance = 0
+	assert.Error(t, updater.scaleTrainers(scaled))
+	assert.Equal(t, 3, updater.job.Spec.Trainer.MinInstance)
+
+	updater.status.Phase = padv1.PaddleJobPhaseSucceeded
+	scaled.Spec.Trainer.MinInstance = 1
+	assert.NoError(t, updater.scaleTrainers(scaled))
+	assert.Equal(t, 3, updater.job.Spec.Trainer.MinInstance)
+}
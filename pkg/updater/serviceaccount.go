@@ -0,0 +1,129 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	log "github.com/golang/glog"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// trainerServiceAccountName returns the name of the service account, role
+// and role binding the operator creates for the trainers of job.
+func trainerServiceAccountName(job *padv1.PaddleJob) string {
+	return job.Name + "-trainer"
+}
+
+// needServiceAccount returns true if the operator creates the service
+// account of the trainers of the job.
+func (updater *PaddleJobUpdater) needServiceAccount() bool {
+	return updater.config.CreateServiceAccounts && updater.job.Spec.ServiceAccountName == ""
+}
+
+// ownerReference returns the reference to job set on the resources created
+// for it, so they are garbage collected with the job.
+func ownerReference(job *padv1.PaddleJob) metav1.OwnerReference {
+	controller := true
+	return metav1.OwnerReference{
+		APIVersion: padv1.SchemeGroupVersion.String(),
+		Kind:       padv1.CRDKind,
+		Name:       job.Name,
+		UID:        job.UID,
+		Controller: &controller,
+	}
+}
+
+// createServiceAccount creates a service account for the trainers of the
+// job which can only read the pods of the job namespace. It may also patch
+// them if the trainers are allowed to report their progress in an
+// annotation.
+func (updater *PaddleJobUpdater) createServiceAccount() error {
+	job := updater.job
+	name := trainerServiceAccountName(job)
+	meta := metav1.ObjectMeta{
+		Name:            name,
+		Namespace:       job.Namespace,
+		Labels:          objectLabels(job, roleContainerName(padv1.Trainer)),
+		OwnerReferences: []metav1.OwnerReference{ownerReference(job)},
+	}
+
+	log.Infof("Create service account namespace=%v name=%v", job.Namespace, name)
+	sa := &corev1.ServiceAccount{ObjectMeta: meta}
+	if _, err := updater.kubeClient.CoreV1().ServiceAccounts(job.Namespace).Create(sa); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	verbs := []string{"get", "list", "watch"}
+	if updater.config.ProgressAnnotation {
+		verbs = append(verbs, "patch")
+	}
+	role := &rbacv1.Role{
+		ObjectMeta: meta,
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"pods"},
+				Verbs:     verbs,
+			},
+		},
+	}
+	if _, err := updater.kubeClient.RbacV1().Roles(job.Namespace).Create(role); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	binding := &rbacv1.RoleBinding{
+		ObjectMeta: meta,
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     name,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      name,
+				Namespace: job.Namespace,
+			},
+		},
+	}
+	if _, err := updater.kubeClient.RbacV1().RoleBindings(job.Namespace).Create(binding); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// deleteServiceAccount deletes the service account, role and role binding
+// created by createServiceAccount.
+func (updater *PaddleJobUpdater) deleteServiceAccount() error {
+	job := updater.job
+	name := trainerServiceAccountName(job)
+	log.Infof("Delete service account namespace=%v name=%v", job.Namespace, name)
+
+	if err := updater.kubeClient.RbacV1().RoleBindings(job.Namespace).Delete(name, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err := updater.kubeClient.RbacV1().Roles(job.Namespace).Delete(name, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err := updater.kubeClient.CoreV1().ServiceAccounts(job.Namespace).Delete(name, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"sort"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// JobName returns the name of the PaddleJob a pod belongs to, or an empty
+// string if the pod does not belong to a PaddleJob.
+func JobName(pod *corev1.Pod) string {
+	if name := pod.Labels[JobNameLabel]; name != "" {
+		return name
+	}
+	// The pods created by older operators only have the legacy labels.
+	if name := pod.Labels["paddle-job-pserver"]; name != "" {
+		return name
+	}
+	if name := pod.Labels["paddle-job-heter"]; name != "" {
+		return name
+	}
+	if name := pod.Labels["paddle-job-evaluator"]; name != "" {
+		return name
+	}
+	return pod.Labels["paddle-job"]
+}
+
+// roleContainerName returns the name of the container running the
+// resource tp.
+func roleContainerName(tp padv1.TrainingResourceType) string {
+	switch tp {
+	case padv1.Pserver:
+		return "pserver"
+	case padv1.Heter:
+		return "heter"
+	case padv1.Evaluator:
+		return "evaluator"
+	}
+	return "trainer"
+}
+
+// jobRoles returns the resources of job.
+func jobRoles(job *padv1.PaddleJob) []padv1.TrainingResourceType {
+	if job.Spec.Heter != nil {
+		return []padv1.TrainingResourceType{padv1.Pserver, padv1.Trainer, padv1.Heter}
+	}
+	return []padv1.TrainingResourceType{padv1.Pserver, padv1.Trainer}
+}
+
+// rolePods returns the pods of the resource tp of the job from the pod
+// informer cache.
+func (updater *PaddleJobUpdater) rolePods(tp padv1.TrainingResourceType) ([]*corev1.Pod, error) {
+	return updater.podLister.Pods(updater.job.Namespace).List(roleSelector(updater.job, tp))
+}
+
+// getReplicaStatuses returns the status of the pservers, the trainers, the
+// heters and the evaluator of the job, previous is the last reported status.
+func (updater *PaddleJobUpdater) getReplicaStatuses(previous []*padv1.TrainingResourceStatus) ([]*padv1.TrainingResourceStatus, error) {
+	desired := map[padv1.TrainingResourceType]int{
+		padv1.Pserver: updater.job.Spec.Pserver.MinInstance,
+		padv1.Trainer: updater.job.Spec.Trainer.MinInstance,
+	}
+	if heter := updater.job.Spec.Heter; heter != nil {
+		desired[padv1.Heter] = heter.Replicas
+	}
+	roles := jobRoles(updater.job)
+	if updater.job.Spec.Evaluator != nil {
+		desired[padv1.Evaluator] = 1
+		roles = append(roles, padv1.Evaluator)
+	}
+
+	var statuses []*padv1.TrainingResourceStatus
+	for _, tp := range roles {
+		pods, err := updater.rolePods(tp)
+		if err != nil {
+			return previous, err
+		}
+		statuses = append(statuses, replicaStatus(tp, pods, desired[tp], findReplicaStatus(previous, tp)))
+	}
+	return statuses, nil
+}
+
+func findReplicaStatus(statuses []*padv1.TrainingResourceStatus, tp padv1.TrainingResourceType) *padv1.TrainingResourceStatus {
+	for _, s := range statuses {
+		if s != nil && s.TrainingResourceType == tp {
+			return s
+		}
+	}
+	return nil
+}
+
+// replicaStatus builds the status of the resource tp from its pods, desired
+// is the number of replicas of the resource and previous its last status.
+func replicaStatus(tp padv1.TrainingResourceType, pods []*corev1.Pod, desired int,
+	previous *padv1.TrainingResourceStatus) *padv1.TrainingResourceStatus {
+	sorted := rankPods(pods)
+	status := &padv1.TrainingResourceStatus{
+		TrainingResourceType: tp,
+		ResourceStates:       make(map[padv1.ResourceState]int),
+	}
+	for rank, pod := range sorted {
+		state := podState(pod)
+		status.ResourceStates[state]++
+		status.Pods = append(status.Pods, podStatus(pod, roleContainerName(tp), rank, state))
+	}
+
+	current := padv1.ResourceStateNone
+	if previous != nil {
+		current = previous.State
+	}
+	status.State = nextResourceState(current, status.ResourceStates, desired)
+	return status
+}
+
+// rankPods returns the pods ordered by rank, i.e. by creation time and
+// then by name.
+func rankPods(pods []*corev1.Pod) []*corev1.Pod {
+	sorted := make([]*corev1.Pod, len(pods))
+	copy(sorted, pods)
+	sort.Slice(sorted, func(i, j int) bool {
+		ti, tj := sorted[i].CreationTimestamp.Time, sorted[j].CreationTimestamp.Time
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// podState returns the state of a pod.
+func podState(pod *corev1.Pod) padv1.ResourceState {
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		return padv1.ResourceStateSucceeded
+	case corev1.PodFailed:
+		return padv1.ResourceStateFailed
+	case corev1.PodRunning:
+		if podReady(pod) {
+			return padv1.ResourceStateRunning
+		}
+		return padv1.ResourceStateStarting
+	}
+	return padv1.ResourceStatePending
+}
+
+// readyPods returns the number of ready pods which are neither terminating
+// nor in released.
+func readyPods(pods []*corev1.Pod, released map[string]bool) int32 {
+	var n int32
+	for _, pod := range pods {
+		if pod.DeletionTimestamp == nil && !released[pod.Name] && podState(pod) == padv1.ResourceStateRunning {
+			n++
+		}
+	}
+	return n
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podStatus returns the status of a pod, container is the name of the
+// container running the role.
+func podStatus(pod *corev1.Pod, container string, rank int, state padv1.ResourceState) padv1.ReplicaPodStatus {
+	s := padv1.ReplicaPodStatus{
+		Name:     pod.Name,
+		NodeName: pod.Spec.NodeName,
+		Rank:     rank,
+		Phase:    pod.Status.Phase,
+		State:    state,
+	}
+	if cs := roleContainerStatus(pod, container); cs != nil {
+		s.RestartCount = cs.RestartCount
+		if t := lastTermination(cs); t != nil {
+			code := t.ExitCode
+			s.LastExitCode = &code
+		}
+	}
+	return s
+}
+
+// roleContainerStatus returns the status of the container named container,
+// or of the only container of the pod.
+func roleContainerStatus(pod *corev1.Pod, container string) *corev1.ContainerStatus {
+	for i := range pod.Status.ContainerStatuses {
+		cs := &pod.Status.ContainerStatuses[i]
+		if cs.Name == container || len(pod.Status.ContainerStatuses) == 1 {
+			return cs
+		}
+	}
+	return nil
+}
+
+// lastTermination returns the current termination of a container, or its
+// last one if it is not terminated.
+func lastTermination(cs *corev1.ContainerStatus) *corev1.ContainerStateTerminated {
+	if cs.State.Terminated != nil {
+		return cs.State.Terminated
+	}
+	return cs.LastTerminationState.Terminated
+}
+
+// nextResourceState moves the state of a resource from current according to
+// the number of its pods in every state and its desired number of replicas.
+// The succeeded state is final, a resource is failed only as long as one of
+// its pods is, the failed pods of a restarted job are deleted.
+func nextResourceState(current padv1.ResourceState, states map[padv1.ResourceState]int, desired int) padv1.ResourceState {
+	switch {
+	case current == padv1.ResourceStateSucceeded:
+		return current
+	case states[padv1.ResourceStateFailed] > 0:
+		return padv1.ResourceStateFailed
+	case desired > 0 && states[padv1.ResourceStateSucceeded] >= desired:
+		return padv1.ResourceStateSucceeded
+	case desired > 0 && states[padv1.ResourceStateRunning]+states[padv1.ResourceStateSucceeded] >= desired:
+		return padv1.ResourceStateRunning
+	case states[padv1.ResourceStatePending] > 0:
+		return padv1.ResourceStatePending
+	case states[padv1.ResourceStateStarting]+states[padv1.ResourceStateRunning] > 0:
+		return padv1.ResourceStateStarting
+	case current == padv1.ResourceStateFailed:
+		// The failed pods are gone, the new ones are not created yet.
+		return padv1.ResourceStateNone
+	}
+	return current
+}
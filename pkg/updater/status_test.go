@@ -0,0 +1,105 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+func testPod(name string, created int64, phase corev1.PodPhase, ready bool) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.Unix(created, 0)},
+		Spec:       corev1.PodSpec{NodeName: "node-" + name},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+	if ready {
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	}
+	return pod
+}
+
+func TestReplicaStatus(t *testing.T) {
+	failed := testPod("c", 1, corev1.PodRunning, false)
+	failed.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:                 "trainer",
+		RestartCount:         2,
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 137}},
+	}}
+	pods := []*corev1.Pod{
+		testPod("b", 2, corev1.PodRunning, true),
+		failed,
+		testPod("a", 2, corev1.PodPending, false),
+	}
+
+	s := replicaStatus(padv1.Trainer, pods, 3, nil)
+	assert.Equal(t, padv1.ResourceState(padv1.ResourceStatePending), s.State)
+	assert.Equal(t, 1, s.ResourceStates[padv1.ResourceStateRunning])
+	assert.Equal(t, 1, s.ResourceStates[padv1.ResourceStateStarting])
+	assert.Equal(t, 1, s.ResourceStates[padv1.ResourceStatePending])
+
+	// Pods are ranked by creation time, then by name.
+	assert.Equal(t, []string{"c", "a", "b"}, []string{s.Pods[0].Name, s.Pods[1].Name, s.Pods[2].Name})
+	assert.Equal(t, 1, s.Pods[1].Rank)
+	assert.Equal(t, "node-c", s.Pods[0].NodeName)
+	assert.Equal(t, int32(2), s.Pods[0].RestartCount)
+	assert.Equal(t, int32(137), *s.Pods[0].LastExitCode)
+
+	pods[1] = testPod("c", 1, corev1.PodRunning, true)
+	pods[2] = testPod("a", 2, corev1.PodRunning, true)
+	s = replicaStatus(padv1.Trainer, pods, 3, s)
+	assert.Equal(t, padv1.ResourceState(padv1.ResourceStateRunning), s.State)
+
+	pods[0].Status.Phase = corev1.PodFailed
+	s = replicaStatus(padv1.Trainer, pods, 3, s)
+	assert.Equal(t, padv1.ResourceState(padv1.ResourceStateFailed), s.State)
+
+	// The failed pod is replaced.
+	pods[0] = testPod("d", 3, corev1.PodRunning, true)
+	s = replicaStatus(padv1.Trainer, pods, 3, s)
+	assert.Equal(t, padv1.ResourceState(padv1.ResourceStateRunning), s.State)
+
+	pods[0].Status.Phase = corev1.PodFailed
+	s = replicaStatus(padv1.Trainer, pods, 3, s)
+	s = replicaStatus(padv1.Trainer, nil, 3, s)
+	assert.Equal(t, padv1.ResourceState(padv1.ResourceStateNone), s.State)
+
+	// Succeeded is final.
+	pods[0].Status.Phase = corev1.PodSucceeded
+	pods[1].Status.Phase = corev1.PodSucceeded
+	pods[2].Status.Phase = corev1.PodSucceeded
+	s = replicaStatus(padv1.Trainer, pods, 3, s)
+	pods[0].Status.Phase = corev1.PodFailed
+	s = replicaStatus(padv1.Trainer, pods, 3, s)
+	assert.Equal(t, padv1.ResourceState(padv1.ResourceStateSucceeded), s.State)
+}
+
+func TestReadyPods(t *testing.T) {
+	ready := testPod("ready", 1, corev1.PodRunning, true)
+	starting := testPod("starting", 2, corev1.PodRunning, false)
+	terminating := testPod("terminating", 3, corev1.PodRunning, true)
+	now := metav1.Now()
+	terminating.DeletionTimestamp = &now
+	released := testPod("released", 4, corev1.PodRunning, true)
+
+	pods := []*corev1.Pod{ready, starting, terminating, released}
+	assert.Equal(t, int32(2), readyPods(pods, nil))
+	assert.Equal(t, int32(1), readyPods(pods, map[string]bool{"released": true}))
+}
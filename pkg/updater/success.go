@@ -0,0 +1,129 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"fmt"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// faultTolerant returns true if job goes on when a trainer fails: its
+// master hands the tasks of the failed trainer out again, or its trainers
+// are elastic.
+func faultTolerant(job *padv1.PaddleJob) bool {
+	return job.Spec.Master != nil || job.Spec.Trainer.MaxInstance > job.Spec.Trainer.MinInstance
+}
+
+// maxTrainerFailures returns the number of trainer failures job tolerates.
+func maxTrainerFailures(job *padv1.PaddleJob) int {
+	if !faultTolerant(job) {
+		return 0
+	}
+	return job.Spec.Trainer.MaxFailures
+}
+
+// minSucceeded returns the number of trainers which have to succeed with
+// the MinTrainers success policy.
+func minSucceeded(job *padv1.PaddleJob) int {
+	if n := job.Spec.Trainer.MinSucceeded; n > 0 {
+		return n
+	}
+	return job.Spec.Trainer.MinInstance
+}
+
+// validateSuccessPolicy validates the success policy and the failure
+// tolerance of the trainers of job.
+func validateSuccessPolicy(job *padv1.PaddleJob) error {
+	t := &job.Spec.Trainer
+	if t.MaxFailures > 0 && !faultTolerant(job) {
+		return fmt.Errorf("trainer failures are only tolerated by fault tolerant jobs, with a master or elastic trainers")
+	}
+	if t.SuccessPolicy == padv1.SuccessPolicyMinTrainers && t.MaxInstance > 0 && minSucceeded(job) > t.MaxInstance {
+		return fmt.Errorf("%d trainers cannot succeed out of at most %d", minSucceeded(job), t.MaxInstance)
+	}
+	return nil
+}
+
+// trainer0 returns the trainer of rank 0 out of pods, or nil if there is
+// none. It is the last created pod annotated with rank 0, the ones created
+// before it were replaced. Until a trainer runs and is annotated, it is the
+// first created trainer which did not fail: the failed trainers which never
+// ran had no rank, they are replaced like any failed trainer.
+func trainer0(pods []*corev1.Pod) *corev1.Pod {
+	var first, ranked *corev1.Pod
+	for _, pod := range rankPods(pods) {
+		if rank, ok := podRank(pod); ok && rank == 0 {
+			ranked = pod
+		}
+		if first == nil && pod.Status.Phase != corev1.PodFailed {
+			if _, ok := podRank(pod); !ok {
+				first = pod
+			}
+		}
+	}
+	if ranked != nil {
+		return ranked
+	}
+	return first
+}
+
+// trainerPhase returns the phase of job decided by its trainer pods and
+// the status of its trainer job, and the reason of the phase. The phase is
+// PaddleJobPhaseNone as long as the trainers neither succeeded nor failed
+// according to the success policy and the failure tolerance of the job.
+func trainerPhase(job *padv1.PaddleJob, pods []*corev1.Pod, s batchv1.JobStatus) (padv1.PaddleJobPhase, string) {
+	policy := job.Spec.Trainer.SuccessPolicy
+	var rank0 *corev1.Pod
+	if policy == padv1.SuccessPolicyTrainer0 {
+		rank0 = trainer0(pods)
+	}
+	if rank0 != nil && rank0.Status.Phase == corev1.PodFailed {
+		return padv1.PaddleJobPhaseFailed, "the trainer of rank 0 failed"
+	}
+	if tolerated := maxTrainerFailures(job); int(s.Failed) > tolerated {
+		if tolerated == 0 {
+			return padv1.PaddleJobPhaseFailed, "at least one trainer failed!"
+		}
+		return padv1.PaddleJobPhaseFailed, fmt.Sprintf("%d trainers failed, %d tolerated", s.Failed, tolerated)
+	}
+
+	switch policy {
+	case padv1.SuccessPolicyTrainer0:
+		if rank0 != nil && rank0.Status.Phase == corev1.PodSucceeded {
+			return padv1.PaddleJobPhaseSucceeded, "the trainer of rank 0 has succeeded"
+		}
+	case padv1.SuccessPolicyAnyTrainer:
+		if s.Succeeded > 0 {
+			return padv1.PaddleJobPhaseSucceeded, "a trainer has succeeded"
+		}
+	case padv1.SuccessPolicyMinTrainers:
+		if n := minSucceeded(job); int(s.Succeeded) >= n {
+			return padv1.PaddleJobPhaseSucceeded, fmt.Sprintf("%d trainers have succeeded", s.Succeeded)
+		}
+	default:
+		parallelism := int32(job.Spec.Trainer.MinInstance)
+		if rs := job.Spec.Trainer.ReplicaSpec; rs != nil && rs.Spec.Parallelism != nil {
+			parallelism = *rs.Spec.Parallelism
+		}
+		if s.Succeeded >= parallelism && s.Active == 0 {
+			return padv1.PaddleJobPhaseSucceeded, "all trainer have succeeded!"
+		}
+	}
+	return padv1.PaddleJobPhaseNone, ""
+}
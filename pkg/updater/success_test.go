@@ -0,0 +1,84 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+func TestTrainerPhase(t *testing.T) {
+	pods := []*corev1.Pod{
+		testPod("rank-1", 2, corev1.PodFailed, false),
+		testPod("rank-0", 1, corev1.PodSucceeded, false),
+		testPod("rank-2", 3, corev1.PodRunning, true),
+	}
+	status := batchv1.JobStatus{Active: 1, Succeeded: 1, Failed: 1}
+
+	cases := []struct {
+		policy      padv1.SuccessPolicy
+		master      bool
+		maxFailures int
+		phase       padv1.PaddleJobPhase
+	}{
+		{padv1.SuccessPolicyAllTrainers, false, 0, padv1.PaddleJobPhaseFailed},
+		{padv1.SuccessPolicyAllTrainers, true, 1, padv1.PaddleJobPhaseNone},
+		{padv1.SuccessPolicyTrainer0, true, 1, padv1.PaddleJobPhaseSucceeded},
+		{padv1.SuccessPolicyAnyTrainer, true, 1, padv1.PaddleJobPhaseSucceeded},
+		{padv1.SuccessPolicyMinTrainers, true, 1, padv1.PaddleJobPhaseNone},
+		// Failures are only tolerated by fault tolerant jobs.
+		{padv1.SuccessPolicyAnyTrainer, false, 1, padv1.PaddleJobPhaseFailed},
+	}
+	for _, c := range cases {
+		job := &padv1.PaddleJob{}
+		job.Spec.Trainer.MinInstance, job.Spec.Trainer.MaxInstance = 3, 3
+		job.Spec.Trainer.SuccessPolicy = c.policy
+		job.Spec.Trainer.MaxFailures = c.maxFailures
+		if c.master {
+			job.Spec.Master = &padv1.MasterSpec{Dataset: "/data/manifest"}
+		}
+		phase, _ := trainerPhase(job, pods, status)
+		assert.Equal(t, c.phase, phase, "%v master=%v maxFailures=%v", c.policy, c.master, c.maxFailures)
+	}
+
+	// The failure of the trainer of rank 0 is never tolerated.
+	job := &padv1.PaddleJob{}
+	job.Spec.Master = &padv1.MasterSpec{Dataset: "/data/manifest"}
+	job.Spec.Trainer.SuccessPolicy = padv1.SuccessPolicyTrainer0
+	job.Spec.Trainer.MaxFailures = 2
+	failed := testPod("rank-0", 1, corev1.PodFailed, false)
+	failed.Annotations = map[string]string{RankAnnotation: "0"}
+	phase, reason := trainerPhase(job, []*corev1.Pod{failed}, batchv1.JobStatus{Failed: 1})
+	assert.EqualValues(t, padv1.PaddleJobPhaseFailed, phase)
+	assert.Equal(t, "the trainer of rank 0 failed", reason)
+
+	// The failed trainer of rank 0 was replaced, e.g. by a restart.
+	replacement := testPod("replacement", 4, corev1.PodSucceeded, false)
+	replacement.Annotations = map[string]string{RankAnnotation: "0"}
+	phase, _ = trainerPhase(job, []*corev1.Pod{replacement, failed}, batchv1.JobStatus{Succeeded: 1, Failed: 1})
+	assert.EqualValues(t, padv1.PaddleJobPhaseSucceeded, phase)
+
+	// The first trainer failed before it ran and had no rank.
+	phase, _ = trainerPhase(job, []*corev1.Pod{testPod("early", 1, corev1.PodFailed, false), testPod("next", 2, corev1.PodRunning, true)}, batchv1.JobStatus{Active: 1, Failed: 1})
+	assert.EqualValues(t, padv1.PaddleJobPhaseNone, phase)
+
+	job.Spec.Master = nil
+	assert.Error(t, validateSuccessPolicy(job))
+}
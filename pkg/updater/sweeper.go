@@ -0,0 +1,310 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/golang/glog"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+	paddleJobClient "github.com/paddlepaddle/paddlejob/pkg/client/clientset/versioned"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// childLabelKeys are the labels the children of the PaddleJobs are
+// found by, the children created by older operators only have the legacy
+// labels of the trainers and the pservers.
+var childLabelKeys = []string{JobNameLabel, "paddle-job", "paddle-job-pserver"}
+
+// childJobName returns the name of the PaddleJob the child with the labels
+// l belongs to, or an empty string if it does not belong to one.
+func childJobName(l labels.Set) string {
+	for _, key := range childLabelKeys {
+		if name := l[key]; name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// childJobUID returns the UID of the PaddleJob the child with meta was
+// created for, or an empty string if it is unknown.
+func childJobUID(meta metav1.ObjectMeta) types.UID {
+	if uid := meta.Labels[JobUIDLabel]; uid != "" {
+		return types.UID(uid)
+	}
+	for _, ref := range meta.OwnerReferences {
+		if ref.Kind == padv1.CRDKind {
+			return ref.UID
+		}
+	}
+	return ""
+}
+
+// orphanReason returns why the child with meta is orphaned, or an empty
+// string if its PaddleJob exists. jobs maps the namespace/name of the
+// existing PaddleJobs to their UID.
+func orphanReason(meta metav1.ObjectMeta, jobs map[string]types.UID) string {
+	name := childJobName(meta.Labels)
+	if name == "" {
+		return ""
+	}
+	uid, ok := jobs[meta.Namespace+"/"+name]
+	if !ok {
+		return fmt.Sprintf("PaddleJob %v does not exist", name)
+	}
+	if owner := childJobUID(meta); owner != "" && owner != uid {
+		return fmt.Sprintf("PaddleJob %v has UID %v instead of %v", name, uid, owner)
+	}
+	return ""
+}
+
+// OrphanPolicy is what the sweeper does with the orphaned children of the
+// PaddleJobs.
+type OrphanPolicy string
+
+const (
+	// OrphanPolicyReport logs the orphans and records a warning event on
+	// each of them.
+	OrphanPolicyReport OrphanPolicy = "Report"
+	// OrphanPolicyDelete deletes the orphans.
+	OrphanPolicyDelete OrphanPolicy = "Delete"
+)
+
+// SweeperConfig is the configuration of the sweeper.
+type SweeperConfig struct {
+	// Interval is the time between two sweeps, zero disables the sweeper.
+	Interval time.Duration
+	// Policy is what is done with the orphans.
+	Policy OrphanPolicy
+	// DryRun only logs what the policy would do with the orphans.
+	DryRun bool
+}
+
+// child is a replicaset, a job or a pod labelled as a child of a
+// PaddleJob.
+type child struct {
+	apiVersion string
+	kind       string
+	meta       metav1.ObjectMeta
+}
+
+// Sweeper periodically finds the replicasets, the jobs and the pods
+// labelled as children of a PaddleJob which does not exist anymore, or
+// whose UID does not match. The updaters only live in the memory of the
+// operator and release the resources of a job on a best effort basis, the
+// sweeper catches what they leave behind.
+type Sweeper struct {
+	kubeClient      kubernetes.Interface
+	paddleJobClient paddleJobClient.Interface
+	config          SweeperConfig
+}
+
+// NewSweeper creates a new Sweeper.
+func NewSweeper(kubeClient kubernetes.Interface, paddleJobClient paddleJobClient.Interface, config SweeperConfig) *Sweeper {
+	return &Sweeper{
+		kubeClient:      kubeClient,
+		paddleJobClient: paddleJobClient,
+		config:          config,
+	}
+}
+
+// Run sweeps every interval until stopCh is closed.
+func (s *Sweeper) Run(stopCh <-chan struct{}) {
+	wait.Until(func() {
+		if err := s.Sweep(); err != nil {
+			log.Errorf("sweep orphaned children of PaddleJobs error: %v", err)
+		}
+	}, s.config.Interval, stopCh)
+}
+
+// Sweep handles the orphans found once according to the policy.
+func (s *Sweeper) Sweep() error {
+	// The children are listed before the jobs, a child created after its
+	// job is never taken for an orphan.
+	children, err := s.listChildren()
+	if err != nil {
+		return err
+	}
+	list, err := s.paddleJobClient.PaddlepaddleV1().PaddleJobs(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	jobs := make(map[string]types.UID, len(list.Items))
+	for _, job := range list.Items {
+		jobs[job.Namespace+"/"+job.Name] = job.UID
+	}
+
+	orphans := 0
+	for _, c := range children {
+		if c.meta.DeletionTimestamp != nil {
+			continue
+		}
+		reason := orphanReason(c.meta, jobs)
+		if reason == "" {
+			continue
+		}
+		orphans++
+		if err := s.handle(c, reason); err != nil {
+			log.Errorf("sweep %v namespace=%v name=%v error: %v", c.kind, c.meta.Namespace, c.meta.Name, err)
+		}
+	}
+	if orphans > 0 {
+		log.Infof("Found %d orphaned children of PaddleJobs", orphans)
+	}
+	return nil
+}
+
+// listChildren lists the replicasets, the jobs and the pods labelled as
+// children of a PaddleJob in all namespaces. The pods controlled by a
+// replicaset or a job go with it and are left out.
+func (s *Sweeper) listChildren() ([]child, error) {
+	var children []child
+	seen := map[types.UID]bool{}
+	add := func(apiVersion, kind string, meta metav1.ObjectMeta) {
+		if !seen[meta.UID] {
+			seen[meta.UID] = true
+			children = append(children, child{apiVersion: apiVersion, kind: kind, meta: meta})
+		}
+	}
+	for _, key := range childLabelKeys {
+		// A selector with a bare key matches the objects with the label.
+		opts := metav1.ListOptions{LabelSelector: key}
+		rss, err := s.kubeClient.ExtensionsV1beta1().ReplicaSets(metav1.NamespaceAll).List(opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, rs := range rss.Items {
+			add("extensions/v1beta1", "ReplicaSet", rs.ObjectMeta)
+		}
+		jobs, err := s.kubeClient.BatchV1().Jobs(metav1.NamespaceAll).List(opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, j := range jobs.Items {
+			add("batch/v1", "Job", j.ObjectMeta)
+		}
+		pods, err := s.kubeClient.CoreV1().Pods(metav1.NamespaceAll).List(opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range pods.Items {
+			if metav1.GetControllerOf(&pod) == nil {
+				add("v1", "Pod", pod.ObjectMeta)
+			}
+		}
+	}
+	return children, nil
+}
+
+// handle reports or deletes the orphan c according to the policy.
+func (s *Sweeper) handle(c child, reason string) error {
+	if s.config.Policy != OrphanPolicyDelete {
+		log.Warningf("Orphaned %v namespace=%v name=%v: %v", c.kind, c.meta.Namespace, c.meta.Name, reason)
+		if s.config.DryRun {
+			return nil
+		}
+		return s.recordEvent(c, reason)
+	}
+	if s.config.DryRun {
+		log.Infof("Would delete orphaned %v namespace=%v name=%v: %v", c.kind, c.meta.Namespace, c.meta.Name, reason)
+		return nil
+	}
+
+	log.Infof("Delete orphaned %v namespace=%v name=%v: %v", c.kind, c.meta.Namespace, c.meta.Name, reason)
+	propagation := metav1.DeletePropagationBackground
+	// The precondition keeps a new child with the same name from being
+	// deleted.
+	uid := c.meta.UID
+	opts := &metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+		Preconditions:     &metav1.Preconditions{UID: &uid},
+	}
+	var err error
+	switch c.kind {
+	case "ReplicaSet":
+		err = s.kubeClient.ExtensionsV1beta1().ReplicaSets(c.meta.Namespace).Delete(c.meta.Name, opts)
+	case "Job":
+		err = s.kubeClient.BatchV1().Jobs(c.meta.Namespace).Delete(c.meta.Name, opts)
+	case "Pod":
+		err = s.kubeClient.CoreV1().Pods(c.meta.Namespace).Delete(c.meta.Name, opts)
+	}
+	if errors.IsNotFound(err) || errors.IsConflict(err) {
+		return nil
+	}
+	return err
+}
+
+// orphanEventName returns the name of the event reporting the orphan c,
+// the same for every sweep so the reports of an orphan are aggregated.
+func orphanEventName(c child) string {
+	return fmt.Sprintf("%v.orphaned.%v", c.meta.Name, c.meta.UID)
+}
+
+// orphanEvent returns the warning event reporting the orphan c at now, or
+// previous counting one more report if the orphan was already reported.
+func orphanEvent(previous *corev1.Event, c child, reason string, now metav1.Time) *corev1.Event {
+	if previous != nil {
+		event := previous.DeepCopy()
+		event.Message = reason
+		event.LastTimestamp = now
+		event.Count++
+		return event
+	}
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      orphanEventName(c),
+			Namespace: c.meta.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: c.apiVersion,
+			Kind:       c.kind,
+			Name:       c.meta.Name,
+			Namespace:  c.meta.Namespace,
+			UID:        c.meta.UID,
+		},
+		Reason:         "Orphaned",
+		Message:        reason,
+		Type:           corev1.EventTypeWarning,
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Source:         corev1.EventSource{Component: "paddle-operator"},
+	}
+}
+
+// recordEvent records a warning event on the orphan c, or counts one more
+// report in the event of a previous sweep.
+func (s *Sweeper) recordEvent(c child, reason string) error {
+	events := s.kubeClient.CoreV1().Events(c.meta.Namespace)
+	previous, err := events.Get(orphanEventName(c), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = events.Create(orphanEvent(nil, c, reason, metav1.Now()))
+		return err
+	} else if err != nil {
+		return err
+	}
+	_, err = events.Update(orphanEvent(previous, c, reason, metav1.Now()))
+	return err
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+)
+
+func TestOrphanReason(t *testing.T) {
+	job := &padv1.PaddleJob{ObjectMeta: metav1.ObjectMeta{Name: "mnist", Namespace: "ns", UID: "1"}}
+	jobs := map[string]types.UID{"ns/mnist": "1"}
+
+	rs := metav1.ObjectMeta{Name: "mnist-pserver", Namespace: "ns", Labels: objectLabels(job, "pserver")}
+	assert.Equal(t, "", orphanReason(rs, jobs))
+	assert.Contains(t, orphanReason(rs, map[string]types.UID{"ns/mnist": "2"}), "UID 2 instead of 1")
+	assert.Contains(t, orphanReason(rs, map[string]types.UID{"other/mnist": "1"}), "does not exist")
+
+	// A pod created before the UID label only has its role label.
+	pod := metav1.ObjectMeta{Name: "mnist-trainer-x", Namespace: "ns", Labels: map[string]string{"paddle-job": "mnist"}}
+	assert.Equal(t, "", orphanReason(pod, map[string]types.UID{"ns/mnist": "2"}))
+	assert.Contains(t, orphanReason(pod, nil), "does not exist")
+
+	master := metav1.ObjectMeta{Namespace: "ns", Labels: selectorLabels(job, masterRole), OwnerReferences: []metav1.OwnerReference{ownerReference(job)}}
+	assert.Contains(t, orphanReason(master, map[string]types.UID{"ns/mnist": "2"}), "instead of 1")
+
+	assert.Equal(t, "", orphanReason(metav1.ObjectMeta{Namespace: "ns"}, nil))
+}
+
+func TestOrphanEvent(t *testing.T) {
+	c := child{apiVersion: "v1", kind: "Pod", meta: metav1.ObjectMeta{Name: "mnist-trainer-x", Namespace: "ns", UID: "1"}}
+	first := metav1.Unix(1000, 0)
+	event := orphanEvent(nil, c, "PaddleJob mnist does not exist", first)
+	assert.Equal(t, "mnist-trainer-x.orphaned.1", event.Name)
+	assert.Equal(t, int32(1), event.Count)
+	assert.Equal(t, types.UID("1"), event.InvolvedObject.UID)
+
+	// The next sweeps count in the same event.
+	next := orphanEvent(event, c, "PaddleJob mnist does not exist", metav1.Unix(1600, 0))
+	assert.Equal(t, event.Name, next.Name)
+	assert.Equal(t, int32(2), next.Count)
+	assert.Equal(t, first, next.FirstTimestamp)
+	assert.Equal(t, int64(1600), next.LastTimestamp.Unix())
+	assert.Equal(t, int32(1), event.Count)
+}
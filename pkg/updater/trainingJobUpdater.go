@@ -1,6 +1,7 @@
 package updater
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"time"
@@ -10,19 +11,19 @@ import (
 	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
 	paddleJobClient "github.com/paddlepaddle/paddlejob/pkg/client/clientset/versioned"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 )
 
 const (
-	retry                 = 5
-	retryTime             = 5 * time.Second
-	convertedTimerTicker  = 10 * time.Second
-	confirmResourceTicker = 5 * time.Second
-	eventChLength         = 1000
-	factor                = 0.8
+	convertedTimerTicker = 10 * time.Second
+	eventChLength        = 1000
+	factor               = 0.8
 )
 
 type paddleJobEventType string
@@ -32,6 +33,45 @@ const (
 	paddleJobEventModify paddleJobEventType = "Modify"
 )
 
+// Config is the operator level configuration shared by all the updaters.
+type Config struct {
+	// Defaults are filled into the spec of every PaddleJob.
+	Defaults JobDefaults
+	// CreateServiceAccounts makes the operator create a service account
+	// allowed to read pods for the trainers of the jobs which do not set
+	// one.
+	CreateServiceAccounts bool
+	// ProgressAnnotation also allows the created service accounts to patch
+	// the pods of their namespace, so the trainers can report their
+	// progress in an annotation of their pod.
+	ProgressAnnotation bool
+	// AcceleratorResources are the resources counted as accelerator
+	// devices, e.g. nvidia.com/gpu.
+	AcceleratorResources []corev1.ResourceName
+	// FailureLogLines is the number of log lines of the first failed pod
+	// of a job kept in its status.
+	FailureLogLines int64
+	// ReadyTimeout is how long the pservers of a job may take to become
+	// ready before the job fails, zero means no limit.
+	ReadyTimeout time.Duration
+	// ReleaseTimeout is how long the trainers of a restarted job may take
+	// to be deleted before the job fails, zero means no limit.
+	ReleaseTimeout time.Duration
+	// UnschedulableTimeout is how long a pod of a job may be unschedulable
+	// before the job fails, zero means no limit.
+	UnschedulableTimeout time.Duration
+	// HostPorts allocates the host ports of the jobs using the host
+	// network, the ports of their spec are used as is if it is nil.
+	HostPorts *PortAllocator
+	// OperatorNamespaceLabels are the labels of the namespace of the
+	// operator, the network policies of the jobs let the pods of the
+	// namespaces matching them connect to the pods of the jobs.
+	OperatorNamespaceLabels map[string]string
+	// MasterImage is the image of the task dispatcher master of the jobs
+	// which do not set one.
+	MasterImage string
+}
+
 type paddleJobEvent struct {
 	// pet is the PaddleJobEventType of PaddleJob
 	pet paddleJobEventType
@@ -50,9 +90,51 @@ type PaddleJobUpdater struct {
 	// PaddleJobClient is the client of PaddleJob.
 	paddleJobClient paddleJobClient.Interface
 
+	// podLister lists the pods of the job from the shared pod informer.
+	podLister corelisters.PodLister
+
+	// config is the operator level configuration.
+	config *Config
+
 	// Status is the status in memory, update when PaddleJob status changed and update the CRD resource status.
 	status padv1.PaddleJobStatus
 
+	// pservers are the restart counts of the pserver pods seen running at
+	// the last check, nil before the first check.
+	pservers map[string]int32
+
+	// creating is when the updater started to create the resources of
+	// the job, zero if it is not creating them.
+	creating time.Time
+	// endpoints is the data of the endpoints ConfigMap of the job at its
+	// last update, nil before the first one.
+	endpoints map[string]string
+	// networkCreated is true once the services and the network policy of
+	// the job exist.
+	networkCreated bool
+	// masterCreated is true once the master and its service exist.
+	masterCreated bool
+	// pserverCreated is true once the pserver replicaset exists.
+	pserverCreated bool
+	// heterCreated is true once the heter replicaset exists.
+	heterCreated bool
+	// evaluatorCreated is true once the evaluator replicaset exists.
+	evaluatorCreated bool
+	// generations are the generations of the replicasets and the trainer
+	// job after the updater last wrote them, by name.
+	generations map[string]int64
+	// released are the pserver pods deleted by a restart, which must not
+	// be counted as ready.
+	released map[string]bool
+
+	// stalled is the last progress time of the trainers when the stall
+	// policy was last applied.
+	stalled time.Time
+
+	// syncCh wakes up the updater when a pod of the job changes. Its
+	// capacity is 1, pending notifications are coalesced.
+	syncCh chan struct{}
+
 	// EventCh receives events from the controller, include Modify and Delete.
 	// When paddleJobEvent is Delete it will delete all resources
 	// The capacity is 1000.
@@ -60,15 +142,21 @@ type PaddleJobUpdater struct {
 }
 
 // NewUpdater creates a new PaddleJobUpdater and start a goroutine to control current job.
-func NewUpdater(job *padv1.PaddleJob, kubeClient kubernetes.Interface, paddleJobClient paddleJobClient.Interface) (*PaddleJobUpdater,
-	error) {
+func NewUpdater(job *padv1.PaddleJob, kubeClient kubernetes.Interface, paddleJobClient paddleJobClient.Interface,
+	podLister corelisters.PodLister, config *Config) (*PaddleJobUpdater, error) {
 	log.Infof("NewJobber namespace=%v name=%v", job.Namespace, job.Name)
+	if config == nil {
+		config = &Config{}
+	}
 	updater := &PaddleJobUpdater{
 		job:               job,
 		kubeClient:        kubeClient,
 		paddleJobClient: paddleJobClient,
+		podLister:         podLister,
+		config:            config,
 		status:            job.Status,
 		eventCh:           make(chan *paddleJobEvent, eventChLength),
+		syncCh:            make(chan struct{}, 1),
 	}
 	go updater.start()
 	return updater, nil
@@ -95,38 +183,54 @@ func (updater *PaddleJobUpdater) Modify(nj *padv1.PaddleJob) {
 	updater.notify(&paddleJobEvent{pet: paddleJobEventModify, job: nj})
 }
 
-func (updater *PaddleJobUpdater) releaseResource(tp padv1.TrainingResourceType) error {
-	resource := new(v1beta1.ReplicaSet)
-	switch tp {
-	case padv1.Pserver:
-		resource = updater.job.Spec.Pserver.ReplicaSpec
+// Sync wakes up the updater to reconcile the job, the controller calls it
+// whenever a pod of the job changes. It never blocks.
+func (updater *PaddleJobUpdater) Sync() {
+	select {
+	case updater.syncCh <- struct{}{}:
+	default:
+	}
+}
+
+// replicaSet returns the replicaset of the resource tp of the job. It
+// returns an error if the job has no such resource or if its replicaset is
+// not generated, e.g. the job failed to parse.
+func (updater *PaddleJobUpdater) replicaSet(tp padv1.TrainingResourceType) (*v1beta1.ReplicaSet, error) {
+	var rs *v1beta1.ReplicaSet
+	switch {
+	case tp == padv1.Pserver:
+		rs = updater.job.Spec.Pserver.ReplicaSpec
+	case tp == padv1.Heter && updater.job.Spec.Heter != nil:
+		rs = updater.job.Spec.Heter.ReplicaSpec
+	case tp == padv1.Evaluator && updater.job.Spec.Evaluator != nil:
+		rs = updater.job.Spec.Evaluator.ReplicaSpec
 	default:
-		return fmt.Errorf("unknow resource")
+		return nil, fmt.Errorf("unknown resource %v", tp)
+	}
+	if rs == nil {
+		return nil, fmt.Errorf("replicaset of %v is not generated", roleContainerName(tp))
+	}
+	return rs, nil
+}
+
+func (updater *PaddleJobUpdater) releaseResource(tp padv1.TrainingResourceType) error {
+	resource, err := updater.replicaSet(tp)
+	if err != nil {
+		return err
 	}
 	var replica int32
 	resource.Spec.Replicas = &replica
-	_, err := updater.kubeClient.ExtensionsV1beta1().ReplicaSets(updater.job.Namespace).Update(resource)
+	_, err = updater.kubeClient.ExtensionsV1beta1().ReplicaSets(updater.job.Namespace).Update(resource)
 	if errors.IsNotFound(err) {
 		return err
 	}
-	key := "paddle-job-" + tp
 
-	labels := Labels(map[string]string{
-		string(key): updater.job.Name,
-	})
-
-	selector, _ := labels.LabelsParser()
 	options := v1.ListOptions{
-		LabelSelector: selector,
+		LabelSelector: roleSelector(updater.job, tp).String(),
 	}
 
-	for j := 0; j <= retry; j++ {
-		time.Sleep(confirmResourceTicker)
-		pl, err := updater.kubeClient.CoreV1().Pods(updater.job.Namespace).List(options)
-		if err == nil && len(pl.Items) == 0 {
-			return nil
-		}
-	}
+	// The scaled down replicaset does not create pods any more, so its pods
+	// are deleted right away instead of waiting for it to delete them.
 	return updater.kubeClient.CoreV1().Pods(updater.job.Namespace).DeleteCollection(&v1.DeleteOptions{}, options)
 }
 
@@ -134,13 +238,17 @@ func (updater *PaddleJobUpdater) releasePserver() error {
 	return updater.releaseResource(padv1.Pserver)
 }
 
+// releaseHeter releases the heters of the job if it has some.
+func (updater *PaddleJobUpdater) releaseHeter() error {
+	if updater.job.Spec.Heter == nil {
+		return nil
+	}
+	return updater.releaseResource(padv1.Heter)
+}
+
 func (updater *PaddleJobUpdater) releaseTrainer() error {
-	labels := Labels(map[string]string{
-		"paddle-job": updater.job.Name,
-	})
-	selector, _ := labels.LabelsParser()
 	options := v1.ListOptions{
-		LabelSelector: selector,
+		LabelSelector: roleSelector(updater.job, padv1.Trainer).String(),
 	}
 
 	return updater.kubeClient.CoreV1().Pods(updater.job.Namespace).DeleteCollection(&v1.DeleteOptions{}, options)
@@ -151,30 +259,65 @@ func (updater *PaddleJobUpdater) deletePaddleJob() error {
 
 	log.Infof("Start to delete PaddleJob namespace=%v name=%v", updater.job.Namespace, updater.job.Name)
 
-	log.Infof("Release pserver, namespace=%v name=%v", updater.job.Namespace, updater.job.Spec.Trainer.ReplicaSpec.Name)
-	if err := updater.releasePserver(); err != nil {
-		log.Error(err.Error())
+	// A job which failed to parse has no replica specs, nor the resources
+	// created from them.
+	for _, tp := range []padv1.TrainingResourceType{padv1.Pserver, padv1.Heter, padv1.Evaluator} {
+		rs, err := updater.replicaSet(tp)
+		if err != nil {
+			continue
+		}
+		log.Infof("Release %v, namespace=%v name=%v", roleContainerName(tp), updater.job.Namespace, rs.Name)
+		if err := updater.releaseResource(tp); err != nil {
+			log.Errorf("release %v error: %v", roleContainerName(tp), err)
+			fault = true
+		}
+		if err := updater.kubeClient.ExtensionsV1beta1().ReplicaSets(updater.job.Namespace).Delete(rs.Name, &v1.DeleteOptions{}); err != nil {
+			log.Errorf("delete %v replicaset error: %v", roleContainerName(tp), err)
+			fault = true
+		}
+	}
+
+	if trainer := updater.job.Spec.Trainer.ReplicaSpec; trainer != nil {
+		log.Infof("Deleting PaddleJob matadata, namespace=%v name=%v", updater.job.Namespace, trainer.Name)
+		if err := updater.kubeClient.BatchV1().Jobs(updater.job.Namespace).Delete(trainer.Name, &v1.DeleteOptions{}); err != nil {
+			log.Error("delete trainer replicaset error: ", err.Error())
+			fault = true
+		}
+
+		log.Infof("Release trainer, namespace=%v name=%v", updater.job.Namespace, trainer.Name)
+		if err := updater.releaseTrainer(); err != nil {
+			log.Error("release trainer  error: ", err.Error())
+			fault = true
+		}
+	}
+
+	if err := updater.releaseMaster(); err != nil {
+		log.Error("release master error: ", err.Error())
 		fault = true
 	}
 
-	log.Infof("Deleting PaddleJob matadata, namespace=%v name=%v", updater.job.Namespace, updater.job.Spec.Pserver.ReplicaSpec.Name)
-	if err := updater.kubeClient.ExtensionsV1beta1().ReplicaSets(updater.job.Namespace).Delete(updater.job.Spec.Pserver.ReplicaSpec.Name, &v1.DeleteOptions{}); err != nil {
-		log.Error("delete pserver replicaset error: ", err.Error())
+	if err := updater.releaseHostPorts(); err != nil {
+		log.Error("release host ports error: ", err.Error())
 		fault = true
 	}
 
-	log.Infof("Deleting PaddleJob matadata, namespace=%v name=%v", updater.job.Namespace, updater.job.Spec.Trainer.ReplicaSpec.Name)
-	if err := updater.kubeClient.BatchV1().Jobs(updater.job.Namespace).Delete(updater.job.Spec.Trainer.ReplicaSpec.Name, &v1.DeleteOptions{}); err != nil {
-		log.Error("delete trainer replicaset error: ", err.Error())
+	if err := updater.deleteNetwork(); err != nil {
+		log.Error("delete network error: ", err.Error())
 		fault = true
 	}
 
-	log.Infof("Release trainer, namespace=%v name=%v", updater.job.Namespace, updater.job.Spec.Trainer.ReplicaSpec.Name)
-	if err := updater.releaseTrainer(); err != nil {
-		log.Error("release trainer  error: ", err.Error())
+	if err := updater.kubeClient.CoreV1().ConfigMaps(updater.job.Namespace).Delete(endpointsName(updater.job), &v1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		log.Error("delete endpoints error: ", err.Error())
 		fault = true
 	}
 
+	if updater.needServiceAccount() {
+		if err := updater.deleteServiceAccount(); err != nil {
+			log.Error("delete service account error: ", err.Error())
+			fault = true
+		}
+	}
+
 	log.Infof("End to delete PaddleJob namespace=%v name=%v", updater.job.Namespace, updater.job.Name)
 
 	if fault {
@@ -183,81 +326,177 @@ func (updater *PaddleJobUpdater) deletePaddleJob() error {
 	return nil
 }
 
+// createResource creates the replicaset of the resource tp if it does not
+// exist. It does not wait for its pods, the updater checks them when they
+// change.
 func (updater *PaddleJobUpdater) createResource(tp padv1.TrainingResourceType) error {
-	resource := new(v1beta1.ReplicaSet)
-	switch tp {
-	case padv1.Pserver:
-		resource = updater.job.Spec.Pserver.ReplicaSpec
-	default:
-		return fmt.Errorf("unknown resource")
+	resource, err := updater.replicaSet(tp)
+	if err != nil {
+		return err
 	}
-	for {
-		_, err := updater.kubeClient.ExtensionsV1beta1().ReplicaSets(updater.job.Namespace).Get(resource.Name, v1.GetOptions{})
-		if errors.IsNotFound(err) {
-			log.Infof("Not found to create namespace=%v name=%v resourceName=%v", updater.job.Namespace, updater.job.Name, resource.Name)
-			_, err = updater.kubeClient.ExtensionsV1beta1().ReplicaSets(updater.job.Namespace).Create(resource)
-			if err != nil {
-				updater.status.Phase = padv1.PaddleJobPhaseFailed
-				updater.status.Reason = "Internal error; create resource error:" + err.Error()
-				return err
-			}
-		} else if err != nil {
-			log.Errorf("Get resource error, namespace=%v name=%v resourceName=%v error=%v", updater.job.Namespace, updater.job.Name, resource.Name, err.Error())
-			time.Sleep(retryTime)
-			continue
+	_, err = updater.kubeClient.ExtensionsV1beta1().ReplicaSets(updater.job.Namespace).Get(resource.Name, v1.GetOptions{})
+	if errors.IsNotFound(err) {
+		log.Infof("Not found to create namespace=%v name=%v resourceName=%v", updater.job.Namespace, updater.job.Name, resource.Name)
+		setSpecHash(&resource.ObjectMeta, resource.Spec)
+		var rs *v1beta1.ReplicaSet
+		rs, err = updater.kubeClient.ExtensionsV1beta1().ReplicaSets(updater.job.Namespace).Create(resource)
+		if err == nil {
+			updater.observeGeneration(rs.ObjectMeta)
+		} else if !errors.IsAlreadyExists(err) {
+			updater.status.Phase = padv1.PaddleJobPhaseFailed
+			updater.status.Reason = "Internal error; create resource error:" + err.Error()
+			return err
 		}
-		ticker := time.NewTicker(confirmResourceTicker)
-		defer ticker.Stop()
-		for v := range ticker.C {
-			rs, err := updater.kubeClient.ExtensionsV1beta1().ReplicaSets(updater.job.Namespace).Get(resource.Name, v1.GetOptions{})
-			log.Infof("Current time %v runing pod is %v, resourceName=%v", v.String(), rs.Status.ReadyReplicas, resource.Name)
-			if err != nil && !errors.IsServerTimeout(err) && !errors.IsTooManyRequests(err) {
-				updater.status.Reason = "Internal error; create resource error:" + err.Error()
-				return err
-			}
-			if errors.IsServerTimeout(err) || errors.IsTooManyRequests(err) {
-				log.Warningf("Connect to kubernetes failed for reasons=%v, retry next ticker", err.Error())
-				continue
-			}
-			if *resource.Spec.Replicas == 0 {
-				return fmt.Errorf(" PaddleJob is deleting, namespace=%v name=%v ", updater.job.Namespace, updater.job.Name)
+	} else if err != nil {
+		// Retried at the next sync.
+		log.Errorf("Get resource error, namespace=%v name=%v resourceName=%v error=%v", updater.job.Namespace, updater.job.Name, resource.Name, err.Error())
+		return err
+	}
+	return nil
+}
 
-			}
-			if rs.Status.ReadyReplicas == *resource.Spec.Replicas {
-				log.Infof("Create resource done , namespace=%v name=%v resourceName=%v", updater.job.Namespace, updater.job.Name, resource.Name)
-				return nil
-			}
-		}
+// roleReady returns true if all the pods of the resource tp of the job are
+// ready.
+func (updater *PaddleJobUpdater) roleReady(tp padv1.TrainingResourceType) (bool, error) {
+	resource, err := updater.replicaSet(tp)
+	if err != nil {
+		return false, err
+	}
+	pods, err := updater.rolePods(tp)
+	if err != nil {
+		return false, err
+	}
+	replicas := int32(1)
+	if r := resource.Spec.Replicas; r != nil {
+		replicas = *r
+	}
+	ready := readyPods(pods, updater.released)
+	log.Infof("%v of %v %vs are ready, namespace=%v name=%v", ready, replicas, roleContainerName(tp), updater.job.Namespace, updater.job.Name)
+	return ready >= replicas, nil
+}
+
+// notReady fails the job if the pods of role are pending for a reason they
+// cannot get over, or are still not ready after the ready timeout.
+func (updater *PaddleJobUpdater) notReady(role string) {
+	updater.updatePending(&updater.status)
+	if updater.status.Phase == padv1.PaddleJobPhaseFailed {
+		return
+	}
+	if timeout := updater.config.ReadyTimeout; timeout > 0 && time.Since(updater.creating) > timeout {
+		updater.status.Phase = padv1.PaddleJobPhaseFailed
+		updater.status.Reason = fmt.Sprintf("%v are not ready after %v", role, timeout)
 	}
 }
 
+// createTrainer creates the trainer job if it does not exist and moves the
+// job to running. The trainer job of a restarted job is created once the
+// previous one is deleted.
 func (updater *PaddleJobUpdater) createTrainer() error {
 	resource := updater.job.Spec.Trainer.ReplicaSpec
-	for {
-		_, err := updater.kubeClient.BatchV1().Jobs(updater.job.Namespace).Get(resource.Name, v1.GetOptions{})
-		if errors.IsNotFound(err) {
-			log.Infof("not found to create trainer namespace=%v name=%v", updater.job.Namespace, updater.job.Name)
-			_, err = updater.kubeClient.BatchV1().Jobs(updater.job.Namespace).Create(resource)
-			if err != nil {
-				updater.status.Phase = padv1.PaddleJobPhaseFailed
-				updater.status.Reason = "Internal error; create trainer error:" + err.Error()
-				return err
-			}
-		} else if err != nil {
-			log.Errorf("Get resource error, namespace=%v name=%v resourceName=%v error=%v", updater.job.Namespace, updater.job.Name, resource.Name, err.Error())
-			time.Sleep(retryTime)
-			continue
+	j, err := updater.kubeClient.BatchV1().Jobs(updater.job.Namespace).Get(resource.Name, v1.GetOptions{})
+	if errors.IsNotFound(err) {
+		log.Infof("not found to create trainer namespace=%v name=%v", updater.job.Namespace, updater.job.Name)
+		setSpecHash(&resource.ObjectMeta, resource.Spec)
+		j, err = updater.kubeClient.BatchV1().Jobs(updater.job.Namespace).Create(resource)
+		if err == nil {
+			updater.observeGeneration(j.ObjectMeta)
+		} else if !errors.IsAlreadyExists(err) {
+			updater.status.Phase = padv1.PaddleJobPhaseFailed
+			updater.status.Reason = "Internal error; create trainer error:" + err.Error()
+			return err
+		}
+	} else if err != nil {
+		log.Errorf("Get resource error, namespace=%v name=%v resourceName=%v error=%v", updater.job.Namespace, updater.job.Name, resource.Name, err.Error())
+		return err
+	} else if j.DeletionTimestamp != nil {
+		log.Infof("wait for trainer to be deleted namespace=%v name=%v", updater.job.Namespace, updater.job.Name)
+		if timeout := updater.config.ReleaseTimeout; timeout > 0 && time.Since(j.DeletionTimestamp.Time) > timeout {
+			updater.status.Phase = padv1.PaddleJobPhaseFailed
+			updater.status.Reason = fmt.Sprintf("trainer job %v is not deleted after %v", resource.Name, timeout)
 		}
-		updater.status.Phase = padv1.PaddleJobPhaseRunning
-		updater.status.Reason = ""
 		return nil
 	}
+	updater.creating = time.Time{}
+	updater.released = nil
+	updater.status.Phase = padv1.PaddleJobPhaseRunning
+	updater.status.Reason = ""
+	return nil
 }
 
+// createPaddleJob creates the resources of the job step by step without
+// blocking: it is called again whenever a pod of the job changes until the
+// job is running, or failed if its pservers or heters are not ready in
+// time. The heters are created once the pservers are ready, the trainers
+// once the heters are.
 func (updater *PaddleJobUpdater) createPaddleJob() error {
-	if err := updater.createResource(padv1.Pserver); err != nil {
+	if updater.creating.IsZero() {
+		updater.creating = time.Now()
+	}
+	if updater.needServiceAccount() {
+		if err := updater.createServiceAccount(); err != nil {
+			updater.status.Phase = padv1.PaddleJobPhaseFailed
+			updater.status.Reason = "Internal error; create service account error:" + err.Error()
+			return err
+		}
+	}
+	if err := updater.syncEndpoints(); err != nil {
+		updater.status.Phase = padv1.PaddleJobPhaseFailed
+		updater.status.Reason = "Internal error; update endpoints error:" + err.Error()
+		return err
+	}
+	if !updater.networkCreated {
+		if err := updater.createNetwork(); err != nil {
+			updater.status.Phase = padv1.PaddleJobPhaseFailed
+			updater.status.Reason = "Internal error; create network error:" + err.Error()
+			return err
+		}
+		updater.networkCreated = true
+	}
+	if updater.job.Spec.Master != nil && !updater.masterCreated {
+		if err := updater.createMaster(); err != nil {
+			updater.status.Phase = padv1.PaddleJobPhaseFailed
+			updater.status.Reason = "Internal error; create master error:" + err.Error()
+			return err
+		}
+		updater.masterCreated = true
+	}
+	if !updater.pserverCreated {
+		if err := updater.createResource(padv1.Pserver); err != nil {
+			return err
+		}
+		updater.pserverCreated = true
+	}
+
+	ready, err := updater.roleReady(padv1.Pserver)
+	if err != nil {
 		return err
 	}
+	if !ready {
+		updater.notReady("pservers")
+		return nil
+	}
+	if updater.job.Spec.Heter != nil {
+		if !updater.heterCreated {
+			if err := updater.createResource(padv1.Heter); err != nil {
+				return err
+			}
+			updater.heterCreated = true
+		}
+		ready, err := updater.roleReady(padv1.Heter)
+		if err != nil {
+			return err
+		}
+		if !ready {
+			updater.notReady("heters")
+			return nil
+		}
+	}
+	if updater.job.Spec.Evaluator != nil && !updater.evaluatorCreated {
+		if err := updater.createResource(padv1.Evaluator); err != nil {
+			return err
+		}
+		updater.evaluatorCreated = true
+	}
 	return updater.createTrainer()
 }
 
@@ -265,9 +504,33 @@ func (updater *PaddleJobUpdater) updateCRDStatus() error {
 	if reflect.DeepEqual(updater.status, updater.job.Status) {
 		return nil
 	}
-	newPaddleJob := updater.job
-	newPaddleJob.Status = updater.status
-	newPaddleJob, err := updater.paddleJobClient.PaddlepaddleV1().PaddleJobs(updater.job.Namespace).Update(newPaddleJob)
+	// Only the status is patched, the spec may have been modified since
+	// the job was read and is left as it is.
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "add", "path": "/status", "value": updater.status},
+	})
+	if err != nil {
+		return err
+	}
+	newPaddleJob, err := updater.paddleJobClient.PaddlepaddleV1().PaddleJobs(updater.job.Namespace).Patch(updater.job.Name, types.JSONPatchType, patch)
+	if err != nil {
+		return err
+	}
+	updater.job.ResourceVersion = newPaddleJob.ResourceVersion
+	updater.job.Status = newPaddleJob.Status
+	return nil
+}
+
+// persistDefaults writes the defaults of the job spec back to the PaddleJob
+// resource. It is the fallback of the defaulting webhook, which may not be
+// deployed or may have been bypassed.
+func (updater *PaddleJobUpdater) persistDefaults() error {
+	job := updater.job.DeepCopy()
+	if !SetDefaults(job, &updater.config.Defaults) {
+		return nil
+	}
+	log.Infof("Persist defaults of PaddleJob namespace=%v name=%v", job.Namespace, job.Name)
+	newPaddleJob, err := updater.paddleJobClient.PaddlepaddleV1().PaddleJobs(job.Namespace).Update(job)
 	if err != nil {
 		return err
 	}
@@ -283,29 +546,57 @@ func (updater *PaddleJobUpdater) parsePaddleJob() {
 		return
 	}
 
-	var parser DefaultJobParser
-	var creatErr error
-	updater.job, creatErr = parser.NewPaddleJob(updater.job)
+	if err := updater.persistDefaults(); err != nil {
+		log.Warning("persist defaults of PaddleJob error: ", err.Error())
+	}
 
-	if creatErr != nil {
+	if err := updater.parse(); err != nil {
 		updater.status.Phase = padv1.PaddleJobPhaseFailed
-		updater.status.Reason = creatErr.Error()
+		updater.status.Reason = err.Error()
 	} else {
 		updater.status.Phase = padv1.PaddleJobPhaseCreating
 		updater.status.Reason = ""
 	}
 }
 
-func (updater *PaddleJobUpdater) getTrainerReplicaStatuses() ([]*padv1.TrainingResourceStatus, error) {
-	var replicaStatuses []*padv1.TrainingResourceStatus
-	trs := padv1.TrainingResourceStatus{
-		TrainingResourceType: padv1.Trainer,
-		State:                padv1.ResourceStateNone,
-		ResourceStates:       make(map[padv1.ResourceState]int),
+// parse allocates the host ports of the job and generates its replica specs.
+func (updater *PaddleJobUpdater) parse() error {
+	if err := updater.allocateHostPorts(); err != nil {
+		return fmt.Errorf("allocate host ports error: %v", err)
+	}
+
+	parser := DefaultJobParser{
+		Defaults:             &updater.config.Defaults,
+		AcceleratorResources: updater.config.AcceleratorResources,
+	}
+	job, err := parser.NewPaddleJob(updater.job)
+	if err != nil {
+		return err
+	}
+	updater.job = job
+	if updater.needServiceAccount() {
+		template := &updater.job.Spec.Trainer.ReplicaSpec.Spec.Template
+		if template.Spec.ServiceAccountName == "" {
+			template.Spec.ServiceAccountName = trainerServiceAccountName(updater.job)
+		}
+	}
+	return nil
+}
+
+// reparse generates the replica specs of a job which was parsed before
+// but has none, v1beta2 has no place for them and a job written through it
+// loses them.
+func (updater *PaddleJobUpdater) reparse() {
+	if updater.status.Phase == padv1.PaddleJobPhaseNone || updater.job.Spec.Trainer.ReplicaSpec != nil {
+		return
+	}
+	log.Infof("Generate the missing replica specs of PaddleJob namespace=%v name=%v", updater.job.Namespace, updater.job.Name)
+	if err := updater.parse(); err != nil {
+		log.Errorf("parse PaddleJob namespace=%v name=%v error: %v", updater.job.Namespace, updater.job.Name, err)
+		// The job cannot go on without its replica specs.
+		updater.status.Phase = padv1.PaddleJobPhaseFailed
+		updater.status.Reason = err.Error()
 	}
-	// TODO(ZhengQi): get detail status in future
-	replicaStatuses = append(replicaStatuses, &trs)
-	return replicaStatuses, nil
 }
 
 // GetStatus get PaddleJob status from trainers.
@@ -320,18 +611,40 @@ func (updater *PaddleJobUpdater) GetStatus() (*padv1.PaddleJobStatus, error) {
 		return &status, err
 	}
 
-	status.ReplicaStatuses, err = updater.getTrainerReplicaStatuses()
+	status.Trainers = int(j.Status.Active)
+	status.ReplicaStatuses, err = updater.getReplicaStatuses(status.ReplicaStatuses)
 	if err != nil {
 		log.Error("get trainer replica status error:", err.Error())
 	}
-	if j.Status.Failed != 0 {
-		status.Phase = padv1.PaddleJobPhaseFailed
-		status.Reason = "at least one trainer failed!"
-	} else {
-		if j.Status.Succeeded == *updater.job.Spec.Trainer.ReplicaSpec.Spec.Parallelism && j.Status.Active == 0 {
-			status.Phase = padv1.PaddleJobPhaseSucceeded
-			status.Reason = "all trainer have succeeded!"
+	updater.updatePending(&status)
+	status.Progress = updater.trainerProgress(status.Progress)
+	if updater.job.Spec.Checkpoint != nil {
+		status.Checkpoint = updater.trainerCheckpoint(status.Checkpoint)
+		updater.collectCheckpoints(&status)
+	}
+	if status.Failure == nil {
+		status.Failure = updater.diagnoseFailure()
+	}
+	// The evaluator does not count in the success of the job, it only
+	// fails the job if its failure policy says so.
+	updater.checkEvaluator(&status)
+	if status.Phase == padv1.PaddleJobPhaseFailed {
+		return &status, nil
+	}
+	trainers, err := updater.rolePods(padv1.Trainer)
+	if err != nil {
+		log.Error("list trainers error:", err.Error())
+	}
+	switch phase, reason := trainerPhase(updater.job, trainers, j.Status); phase {
+	case padv1.PaddleJobPhaseFailed:
+		status.Phase = phase
+		status.Reason = reason
+		if status.Failure != nil && maxTrainerFailures(updater.job) == 0 {
+			status.Reason = failureReason(status.Failure)
 		}
+	case padv1.PaddleJobPhaseSucceeded:
+		status.Phase = phase
+		status.Reason = reason
 	}
 
 	return &status, nil
@@ -342,6 +655,16 @@ func (updater *PaddleJobUpdater) Convert() {
 	log.Infof("convert status, namespace=%v name=%v: ", updater.job.Namespace, updater.job.Name)
 
 	if updater.status.Phase == padv1.PaddleJobPhaseRunning {
+		if err := updater.syncEndpoints(); err != nil {
+			log.Error("update endpoints error: ", err.Error())
+		}
+		if updater.checkPservers() {
+			if err := updater.updateCRDStatus(); err != nil {
+				log.Warning("restart job to update PaddleJob status error: ", err.Error())
+			}
+			return
+		}
+		updater.reconcileDrift()
 		status, err := updater.GetStatus()
 		if err != nil {
 			log.Error("get current status of trainer from k8s error:", err.Error())
@@ -349,6 +672,12 @@ func (updater *PaddleJobUpdater) Convert() {
 		}
 		updater.status = *status.DeepCopy()
 		log.Infof("Current status namespace=%v name=%v status=%v : ", updater.job.Namespace, updater.job.Name, status)
+		if updater.checkStall() {
+			if err := updater.updateCRDStatus(); err != nil {
+				log.Warning("restart stalled job to update PaddleJob status error: ", err.Error())
+			}
+			return
+		}
 		err = updater.updateCRDStatus()
 		if err != nil {
 			log.Warning("get current status to update PaddleJob status error: ", err.Error())
@@ -359,15 +688,28 @@ func (updater *PaddleJobUpdater) Convert() {
 			if err := updater.releasePserver(); err != nil {
 				log.Error(err.Error())
 			}
+			if err := updater.releaseHeter(); err != nil {
+				log.Error(err.Error())
+			}
+			if err := updater.releaseEvaluator(); err != nil {
+				log.Error(err.Error())
+			}
 			log.Infof("Release trainer, namespace=%v name=%v", updater.job.Namespace, updater.job.Spec.Trainer.ReplicaSpec.Name)
 			if err := updater.releaseTrainer(); err != nil {
 				log.Error(err.Error())
 			}
+			if err := updater.releaseMaster(); err != nil {
+				log.Error(err.Error())
+			}
+			if err := updater.releaseHostPorts(); err != nil {
+				log.Error(err.Error())
+			}
 		}
 	}
 }
 
 // InitResource is used to parse PaddleJob and create PaddleJob resources.
+// It does not block, it is called on every sync until the job is running.
 func (updater *PaddleJobUpdater) InitResource() {
 	if updater.status.Phase == padv1.PaddleJobPhaseNone {
 		log.Infof("set up PaddleJob namespace=%v name=%v: ", updater.job.Namespace, updater.job.Name)
@@ -391,15 +733,39 @@ func (updater *PaddleJobUpdater) InitResource() {
 			if err := updater.releasePserver(); err != nil {
 				log.Error(err.Error())
 			}
+			if err := updater.releaseHeter(); err != nil {
+				log.Error(err.Error())
+			}
+			if err := updater.releaseEvaluator(); err != nil {
+				log.Error(err.Error())
+			}
+			if err := updater.releaseMaster(); err != nil {
+				log.Error(err.Error())
+			}
+			if err := updater.releaseHostPorts(); err != nil {
+				log.Error(err.Error())
+			}
 		}
 	}
 }
 
+// reconcile creates the resources of the job or converts its status
+// according to its phase.
+func (updater *PaddleJobUpdater) reconcile() {
+	switch updater.status.Phase {
+	case padv1.PaddleJobPhaseNone, padv1.PaddleJobPhaseCreating:
+		updater.InitResource()
+	case padv1.PaddleJobPhaseRunning:
+		updater.Convert()
+	}
+}
+
 // Start is the main process of life cycle of a PaddleJob, including create resources, event process handle and
 // status convert.
 func (updater *PaddleJobUpdater) start() {
 	log.Infof("start updater, namespace=%v name=%v: ", updater.job.Namespace, updater.job.Name)
-	go updater.InitResource()
+	updater.reparse()
+	updater.InitResource()
 
 	ticker := time.NewTicker(convertedTimerTicker)
 	defer ticker.Stop()
@@ -414,9 +780,15 @@ func (updater *PaddleJobUpdater) start() {
 					log.Errorf(err.Error())
 				}
 				return
+			case paddleJobEventModify:
+				if err := updater.scaleTrainers(ev.job); err != nil {
+					log.Errorf("scale trainers namespace=%v name=%v error: %v", updater.job.Namespace, updater.job.Name, err)
+				}
 			}
+		case <-updater.syncCh:
+			updater.reconcile()
 		case <-ticker.C:
-			updater.Convert()
+			updater.reconcile()
 			if updater.status.Phase == padv1.PaddleJobPhaseSucceeded || updater.status.Phase == padv1.PaddleJobPhaseFailed {
 				if ticker != nil {
 					log.Infof("stop ticker for job has done, namespace=%v name=%v: ", updater.job.Namespace, updater.job.Name)
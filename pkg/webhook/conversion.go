@@ -0,0 +1,139 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	log "github.com/golang/glog"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+	padv1beta2 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1beta2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// ConvertPath is the URL path of the conversion webhook.
+	ConvertPath = "/convert"
+)
+
+// conversionReview is the apiextensions.k8s.io/v1 ConversionReview sent by
+// the API server to convert PaddleJobs between the served versions.
+type conversionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *conversionRequest  `json:"request,omitempty"`
+	Response        *conversionResponse `json:"response,omitempty"`
+}
+
+type conversionRequest struct {
+	UID               types.UID              `json:"uid"`
+	DesiredAPIVersion string                 `json:"desiredAPIVersion"`
+	Objects           []runtime.RawExtension `json:"objects"`
+}
+
+type conversionResponse struct {
+	UID              types.UID              `json:"uid"`
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+	Result           metav1.Status          `json:"result"`
+}
+
+func (s *Server) serveConvert(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := conversionReview{}
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, fmt.Sprintf("invalid conversion review: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	review.Response = convert(review.Request)
+	review.Request = nil
+
+	resp, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(resp); err != nil {
+		log.Errorf("write conversion response error: %v", err)
+	}
+}
+
+// convert converts all the objects of req to the desired version.
+func convert(req *conversionRequest) *conversionResponse {
+	resp := &conversionResponse{UID: req.UID}
+	for _, obj := range req.Objects {
+		converted, err := convertObject(obj.Raw, req.DesiredAPIVersion)
+		if err != nil {
+			log.Errorf("convert PaddleJob to %v error: %v", req.DesiredAPIVersion, err)
+			resp.ConvertedObjects = nil
+			resp.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			return resp
+		}
+		resp.ConvertedObjects = append(resp.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+	resp.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return resp
+}
+
+// convertObject converts the PaddleJob raw to the API version desired.
+func convertObject(raw []byte, desired string) ([]byte, error) {
+	meta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, err
+	}
+	if meta.APIVersion == desired {
+		return raw, nil
+	}
+
+	v1 := padv1.SchemeGroupVersion.String()
+	v1beta2 := padv1beta2.SchemeGroupVersion.String()
+	switch {
+	case meta.APIVersion == v1 && desired == v1beta2:
+		in := &padv1.PaddleJob{}
+		if err := json.Unmarshal(raw, in); err != nil {
+			return nil, err
+		}
+		out := &padv1beta2.PaddleJob{}
+		if err := out.ConvertFrom(in); err != nil {
+			return nil, err
+		}
+		out.TypeMeta = metav1.TypeMeta{APIVersion: desired, Kind: padv1.CRDKind}
+		return json.Marshal(out)
+	case meta.APIVersion == v1beta2 && desired == v1:
+		in := &padv1beta2.PaddleJob{}
+		if err := json.Unmarshal(raw, in); err != nil {
+			return nil, err
+		}
+		out := &padv1.PaddleJob{}
+		if err := in.ConvertTo(out); err != nil {
+			return nil, err
+		}
+		out.TypeMeta = metav1.TypeMeta{APIVersion: desired, Kind: padv1.CRDKind}
+		return json.Marshal(out)
+	}
+	return nil, fmt.Errorf("unsupported conversion from %v to %v", meta.APIVersion, desired)
+}
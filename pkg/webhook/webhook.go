@@ -0,0 +1,234 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhook serves the admission and conversion webhooks of
+// PaddleJob. The mutating webhook fills in the defaults of a PaddleJob
+// before it is persisted, so the stored spec is the one that actually
+// runs. The conversion webhook converts PaddleJobs between the storage
+// version v1 and v1beta2.
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	log "github.com/golang/glog"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+	"github.com/paddlepaddle/paddlejob/pkg/updater"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// MutatePath is the URL path of the defaulting webhook.
+	MutatePath = "/mutate-paddlejob"
+)
+
+// Server serves the admission and conversion webhooks of PaddleJob.
+type Server struct {
+	defaults *updater.JobDefaults
+}
+
+// NewServer creates a webhook server filling the defaults d into PaddleJobs.
+func NewServer(d *updater.JobDefaults) *Server {
+	return &Server{defaults: d}
+}
+
+// Run serves the webhooks over TLS on addr, it blocks until the server fails.
+func (s *Server) Run(addr, certFile, keyFile string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(MutatePath, s.serveMutate)
+	mux.HandleFunc(ConvertPath, s.serveConvert)
+	log.Infof("serving PaddleJob webhooks on %v", addr)
+	return http.ListenAndServeTLS(addr, certFile, keyFile, mux)
+}
+
+// admissionReview is the admission.k8s.io/v1beta1 AdmissionReview sent by
+// the API server to the mutating webhook. Only the fields the webhook uses
+// are declared, the admission API is not part of the pinned k8s.io/api.
+type admissionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *admissionRequest  `json:"request,omitempty"`
+	Response        *admissionResponse `json:"response,omitempty"`
+}
+
+type admissionRequest struct {
+	UID       types.UID               `json:"uid"`
+	Kind      metav1.GroupVersionKind `json:"kind"`
+	Name      string                  `json:"name,omitempty"`
+	Namespace string                  `json:"namespace,omitempty"`
+	Object    runtime.RawExtension    `json:"object,omitempty"`
+}
+
+type admissionResponse struct {
+	UID       types.UID      `json:"uid"`
+	Allowed   bool           `json:"allowed"`
+	Result    *metav1.Status `json:"status,omitempty"`
+	Patch     []byte         `json:"patch,omitempty"`
+	PatchType *string        `json:"patchType,omitempty"`
+}
+
+// patchTypeJSONPatch is the type of the patches of the mutating webhook.
+const patchTypeJSONPatch = "JSONPatch"
+
+// jsonPatch is a single operation of a JSON patch (RFC 6902).
+type jsonPatch struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+func (s *Server) serveMutate(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := admissionReview{}
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, fmt.Sprintf("invalid admission review: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	review.Response = s.mutate(review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+
+	resp, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(resp); err != nil {
+		log.Errorf("write admission response error: %v", err)
+	}
+}
+
+// mutate returns the admission response patching the defaults into the
+// PaddleJob of req. The defaults are those of v1, the PaddleJobs of the other
+// versions are converted to v1 by the API server before they are sent to the
+// webhook, any other request is let through unchanged.
+func (s *Server) mutate(req *admissionRequest) *admissionResponse {
+	if req.Kind.Group != padv1.CRDGroup || req.Kind.Version != padv1.CRDVersion {
+		log.Warningf("not defaulting %v namespace=%v name=%v", req.Kind, req.Namespace, req.Name)
+		return &admissionResponse{Allowed: true}
+	}
+
+	patch, err := defaultsPatch(req.Object.Raw, s.defaults)
+	if err != nil {
+		return &admissionResponse{
+			Result: &metav1.Status{Message: err.Error()},
+		}
+	}
+
+	resp := &admissionResponse{Allowed: true}
+	if patch != nil {
+		log.Infof("defaulting PaddleJob namespace=%v name=%v", req.Namespace, req.Name)
+		pt := patchTypeJSONPatch
+		resp.Patch = patch
+		resp.PatchType = &pt
+	}
+	return resp
+}
+
+// defaultsPatch returns the JSON patch adding the defaults to the v1
+// PaddleJob raw, or nil if its spec has nothing to default. Only the
+// defaulted fields are patched, so the fields of raw which PaddleJob does not
+// know are kept.
+func defaultsPatch(raw []byte, d *updater.JobDefaults) ([]byte, error) {
+	meta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, err
+	}
+	if meta.APIVersion != padv1.SchemeGroupVersion.String() {
+		return nil, fmt.Errorf("unsupported PaddleJob version %v", meta.APIVersion)
+	}
+
+	job := &padv1.PaddleJob{}
+	if err := json.Unmarshal(raw, job); err != nil {
+		return nil, err
+	}
+	defaulted := job.DeepCopy()
+	if !updater.SetDefaults(defaulted, d) {
+		return nil, nil
+	}
+
+	var obj, old, spec map[string]interface{}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, err
+	}
+	if err := remarshal(job.Spec, &old); err != nil {
+		return nil, err
+	}
+	if err := remarshal(defaulted.Spec, &spec); err != nil {
+		return nil, err
+	}
+	current, ok := obj["spec"].(map[string]interface{})
+	if !ok {
+		return json.Marshal([]jsonPatch{{Op: "add", Path: "/spec", Value: spec}})
+	}
+	return json.Marshal(addPatches("/spec", current, old, spec))
+}
+
+// remarshal converts in to out through JSON.
+func remarshal(in, out interface{}) error {
+	raw, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// pointerEscaper escapes the keys of a JSON pointer (RFC 6901).
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// addPatches returns the add operations setting the fields of defaulted which
+// differ from old, the object current decodes to, under path. The operations
+// descend into the objects which exist in current, so they only set the
+// defaulted fields.
+func addPatches(path string, current, old, defaulted map[string]interface{}) []jsonPatch {
+	keys := make([]string, 0, len(defaulted))
+	for k := range defaulted {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var patches []jsonPatch
+	for _, k := range keys {
+		v := defaulted[k]
+		if reflect.DeepEqual(old[k], v) {
+			continue
+		}
+		p := path + "/" + pointerEscaper.Replace(k)
+		c, cok := current[k].(map[string]interface{})
+		o, ook := old[k].(map[string]interface{})
+		d, dok := v.(map[string]interface{})
+		if cok && ook && dok {
+			patches = append(patches, addPatches(p, c, o, d)...)
+			continue
+		}
+		patches = append(patches, jsonPatch{Op: "add", Path: p, Value: v})
+	}
+	return patches
+}
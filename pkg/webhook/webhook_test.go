@@ -0,0 +1,112 @@
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	padv1 "github.com/paddlepaddle/paddlejob/pkg/apis/paddlepaddle/v1"
+	"github.com/paddlepaddle/paddlejob/pkg/updater"
+)
+
+// patchOps decodes the JSON patch raw into its operations by path.
+func patchOps(t *testing.T, raw []byte) map[string]jsonPatch {
+	var patches []jsonPatch
+	assert.Nil(t, json.Unmarshal(raw, &patches))
+	ops := make(map[string]jsonPatch, len(patches))
+	for _, p := range patches {
+		ops[p.Path] = p
+	}
+	return ops
+}
+
+func TestDefaultsPatchNoop(t *testing.T) {
+	job := &padv1.PaddleJob{
+		TypeMeta: metav1.TypeMeta{APIVersion: "paddlepaddle.org/v1", Kind: padv1.CRDKind},
+	}
+	job.Name = "job"
+	updater.SetDefaults(job, nil)
+	raw, err := json.Marshal(job)
+	assert.Nil(t, err)
+
+	patch, err := defaultsPatch(raw, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, patch)
+}
+
+func TestDefaultsPatch(t *testing.T) {
+	raw := []byte(`{
+		"apiVersion": "paddlepaddle.org/v1",
+		"kind": "PaddleJob",
+		"metadata": {"name": "job"},
+		"spec": {
+			"image": "myrepo/paddle",
+			"pserver": {"min-instance": 1, "max-instance": 1},
+			"trainer": {"min-instance": 2, "max-instance": 2, "unknown": "kept"}
+		}
+	}`)
+
+	d := &updater.JobDefaults{
+		Resources:    corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+		NodeSelector: map[string]string{"pool": "paddle"},
+	}
+	patch, err := defaultsPatch(raw, d)
+	assert.Nil(t, err)
+	ops := patchOps(t, patch)
+	for _, p := range ops {
+		assert.Equal(t, "add", p.Op)
+	}
+	assert.Equal(t, float64(7164), ops["/spec/port"].Value)
+	assert.Equal(t, map[string]interface{}{"pool": "paddle"}, ops["/spec/NodeSelector"].Value)
+	requests := map[string]interface{}{"requests": map[string]interface{}{"cpu": "1"}}
+	assert.Equal(t, requests, ops["/spec/pserver/resources"].Value)
+	assert.Equal(t, requests, ops["/spec/trainer/resources"].Value)
+
+	// The spec, the trainer and the fields set by the user are not replaced.
+	assert.NotContains(t, ops, "/spec")
+	assert.NotContains(t, ops, "/spec/trainer")
+	assert.NotContains(t, ops, "/spec/image")
+}
+
+func TestDefaultsPatchUnsupportedVersion(t *testing.T) {
+	raw := []byte(`{"apiVersion": "paddlepaddle.org/v1beta2", "kind": "PaddleJob", "spec": {}}`)
+	patch, err := defaultsPatch(raw, nil)
+	assert.NotNil(t, err)
+	assert.Nil(t, patch)
+}
+
+func TestMutateSkipsOtherVersions(t *testing.T) {
+	s := NewServer(nil)
+	resp := s.mutate(&admissionRequest{
+		Kind:   metav1.GroupVersionKind{Group: padv1.CRDGroup, Version: "v1beta2", Kind: padv1.CRDKind},
+		Object: runtime.RawExtension{Raw: []byte(`{"apiVersion": "paddlepaddle.org/v1beta2", "spec": {}}`)},
+	})
+	assert.True(t, resp.Allowed)
+	assert.Nil(t, resp.Patch)
+
+	resp = s.mutate(&admissionRequest{
+		Kind:   metav1.GroupVersionKind{Group: padv1.CRDGroup, Version: padv1.CRDVersion, Kind: padv1.CRDKind},
+		Object: runtime.RawExtension{Raw: []byte(`{"apiVersion": "paddlepaddle.org/v1", "spec": {}}`)},
+	})
+	assert.True(t, resp.Allowed)
+	assert.NotNil(t, resp.Patch)
+}
@@ -0,0 +1,72 @@
+#!/bin/bash
+
+# Copyright 2019 The Kubeflow Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# This shell generates the structural schema of the PaddleJob CRD in
+# manifests/crd.yaml from the Go types in pkg/apis/paddlepaddle, driven by the
+# +kubebuilder markers on the types, and embeds it into the operator as
+# pkg/crd/zz_generated.crd.go.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+SCRIPT_ROOT=$(dirname ${BASH_SOURCE})/..
+CONTROLLER_GEN=${CONTROLLER_GEN:-$(command -v controller-gen || echo ${GOPATH}/bin/controller-gen)}
+if [[ ! -x ${CONTROLLER_GEN} ]]; then
+  echo "controller-gen not found, install it with:"
+  echo "  go install sigs.k8s.io/controller-tools/cmd/controller-gen@v0.18.0"
+  exit 1
+fi
+
+cd ${SCRIPT_ROOT}
+${CONTROLLER_GEN} crd:crdVersions=v1,maxDescLen=0,generateEmbeddedObjectMeta=true \
+  paths=./pkg/apis/paddlepaddle/... \
+  output:crd:stdout | sed '1{/^---$/d}' > manifests/crd.yaml
+
+# The stored version is v1. The conversion webhook of the operator is only
+# enabled by paddlejob --install-crd once it is served and trusted, until then
+# the versions are not converted and v1beta2 is not served.
+export CONVERSION="  conversion:
+    strategy: None"
+awk '{print} /^spec:$/{print ENVIRON["CONVERSION"]}' manifests/crd.yaml > manifests/crd.yaml.tmp
+mv manifests/crd.yaml.tmp manifests/crd.yaml
+
+{
+  cat <<HEADER
+// Copyright 2019 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+HEADER
+  echo
+  echo "// This file was automatically generated by scripts/update-crd.sh"
+  echo
+  echo "package crd"
+  echo
+  echo "// crdYAML is the PaddleJob CRD of manifests/crd.yaml."
+  echo "const crdYAML = \`"
+  cat manifests/crd.yaml
+  echo "\`"
+} > pkg/crd/zz_generated.crd.go